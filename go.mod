@@ -4,6 +4,17 @@ replace cornchip.com/libwara/v2 => ./libwara/
 
 go 1.19
 
-require cornchip.com/libwara/v2 v2.0.0-00010101000000-000000000000
+require (
+	cornchip.com/libwara/v2 v2.0.0-00010101000000-000000000000
+	github.com/klauspost/compress v1.16.7
+	go.etcd.io/bbolt v1.3.7
+)
 
-require golang.org/x/image v0.3.0 // indirect
+require (
+	github.com/makiuchi-d/gozxing v0.1.1 // indirect
+	github.com/skip2/go-qrcode v0.0.0-20200617195104-da1b6568686e // indirect
+	golang.org/x/image v0.3.0 // indirect
+	golang.org/x/sys v0.4.0 // indirect
+	golang.org/x/text v0.6.0 // indirect
+	golang.org/x/xerrors v0.0.0-20200804184101-5ec99f83aff1 // indirect
+)
@@ -0,0 +1,207 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"cornchip.com/libwara/v2"
+)
+
+// Turns Items into Topics and Posts of a Nup
+type Builder struct {
+	Stats      StatsConfig       // How Topic and Post statistics are derived from the Items
+	Feelings   FeelingClassifier // Picks the Feeling of each Post, nil leaves every Post at FEELING_DEFAULT
+	Formatter  BodyFormatter     // Turns the text of each Item into a Post body
+	Moderation Moderation        // Decides which Items become Posts
+	Languages  *LanguageDetector // Sets the language of Posts in Topics without a configured language
+
+	Client       *Client                           // Fetches subreddit icons, nil keeps every Topic offline
+	IconFallback func(name string) (string, error) // Icon for Topics whose subreddit icon cannot be used, nil keeps the default icon
+	Dither       libwara.Dither                    // How images are reduced to black and white paintings
+
+	CommentsPerItem int // Top comments of each Item that become Posts of their own, 0 leaves comments out
+	Workers         int // Posts built at the same time, 0 uses one per CPU
+
+	History *History // Prefers Items not used in earlier builds and keeps the Mii of each author, nil forgets everything between runs
+	NupName string   // Name the Posts are recorded under in the History
+
+	miis   map[string]string // Encoded Mii of each author
+	miisMu sync.Mutex
+}
+
+// Creates a Builder with the default configuration
+func NewBuilder() *Builder {
+	return &Builder{
+		Stats:      DefaultStats,
+		Feelings:   DefaultFeelingClassifier(),
+		Formatter:  DefaultFormatter,
+		Moderation: DefaultModeration,
+		Languages:  NewLanguageDetector(libwara.LanguageEnglish),
+
+		IconFallback: func(name string) (string, error) {
+			return libwara.CreatePlaceholderIcon(name, true)
+		},
+		Dither: libwara.DitherFloydSteinberg,
+	}
+}
+
+// Adds a Topic to the Nup with a Post for every Item, in order
+// Items removed by moderation or that do not fit within the limits of the Nup are left out
+// On error, including cancellation, the Topic is removed again and the Nup is left as it was
+func (b *Builder) AddTopic(ctx context.Context, n *libwara.Nup, topic TopicConfig, items []Item) (err error) {
+	settings, err := topic.settings()
+	if err != nil {
+		return err
+	}
+
+	expanded, err := b.expandComments(ctx, items)
+	if err != nil {
+		return err
+	}
+	items = b.Moderation.Filter(expanded)
+	if b.History != nil {
+		if items, err = b.History.Prefer(items); err != nil {
+			return err
+		}
+	}
+
+	var handle libwara.TopicHandle
+	if topic.Title != "" {
+		handle, err = n.NewTopicForTitle(topic.Title, settings.region)
+	} else {
+		handle, err = n.NewTopic(topic.Name)
+	}
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			n.DeleteTopic(handle)
+		}
+	}()
+
+	// Posts are added one by one so the limits and the order of the Topic do not depend on scheduling,
+	// then filled in parallel
+	type job struct {
+		item *Item
+		post libwara.PostHandle
+	}
+	jobs := make([]job, 0, len(items))
+	for i := range items {
+		post, err := n.NewPost(handle, libwara.WithAuthor(items[i].Author))
+		if errors.Is(err, libwara.ErrTooManyPeople) || errors.Is(err, libwara.ErrInvalidPost) {
+			continue
+		}
+		if errors.Is(err, libwara.ErrTopicFull) || errors.Is(err, libwara.ErrTooManyPosts) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		jobs = append(jobs, job{&items[i], post})
+	}
+
+	errs := make([]error, len(jobs))
+	err = b.parallel(ctx, len(jobs), func(ctx context.Context, i int) {
+		post, err := n.Post(jobs[i].post)
+		if err != nil {
+			errs[i] = err
+			return
+		}
+		b.fillPost(&post, jobs[i].item, &settings)
+		if jobs[i].item.ImageURL != "" && b.Client != nil {
+			if err := b.addPainting(ctx, &post, jobs[i].item); err != nil {
+				log.Println("could not add painting for " + jobs[i].item.Id + ": " + err.Error())
+			}
+		}
+		errs[i] = n.SetPost(jobs[i].post, post)
+	})
+	if err != nil {
+		return err
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	// The statistics only count the Items that became Posts, not the ones left out
+	added := make([]Item, len(jobs))
+	for i := range jobs {
+		added[i] = *jobs[i].item
+	}
+	t, err := n.Topic(handle)
+	if err != nil {
+		return err
+	}
+	icon := b.topicIcon(ctx, &topic, t.Name)
+	err = n.EditTopic(handle, func(t *libwara.Topic) error {
+		b.fillTopic(t, added)
+		if icon != "" {
+			t.Icon = icon
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Recorded last so Items of a Topic that was rolled back are not counted as used
+	if b.History != nil {
+		used := make([]*Item, len(jobs))
+		for i := range jobs {
+			used[i] = jobs[i].item
+		}
+		if err := b.History.Record(b.NupName, time.Now(), used); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Fills a Post from an Item
+func (b *Builder) fillPost(post *libwara.Post, item *Item, settings *topicSettings) {
+	post.Body = b.Formatter.Format(itemText(item))
+	if mii := b.authorMii(item.Author); mii != "" {
+		post.MiiData = mii
+	}
+	post.CreatedAt = item.CreatedAt.Format("2006-01-02 15:04:05")
+	post.EmpathyCount = strconv.Itoa(b.Stats.EmpathyCount(item.Score))
+	post.ReplyCount = b.Stats.ReplyCount(item.Comments)
+	if item.Spoiler {
+		post.IsSpoiler = 1
+	}
+	if b.Feelings != nil {
+		post.FeelingId = b.Feelings.Classify(item)
+	}
+
+	post.CountryId = settings.country
+	post.RegionId = settings.regionId
+	switch {
+	case settings.language != nil:
+		post.LanguageId = *settings.language
+	case b.Languages != nil:
+		post.LanguageId = b.Languages.Detect(itemText(item))
+	}
+}
+
+// Fills the statistics of a Topic from the Items of its Posts
+func (b *Builder) fillTopic(t *libwara.Topic, items []Item) {
+	t.ParticipantCount = uniqueAuthors(items)
+	t.EmpathyCount = b.Stats.EmpathyCount(totalScore(items))
+}
+
+// Returns the text of an Item, the title followed by the body
+func itemText(item *Item) string {
+	if item.Body == "" {
+		return item.Title
+	}
+	if item.Title == "" {
+		return item.Body
+	}
+	return item.Title + "\n\n" + item.Body
+}
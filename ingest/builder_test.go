@@ -0,0 +1,168 @@
+package ingest
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"cornchip.com/libwara/v2"
+)
+
+// Returns Items by distinct authors
+func testItems(count int) []Item {
+	items := make([]Item, count)
+	for i := range items {
+		items[i] = Item{
+			Id:        "t3_" + strconv.Itoa(i),
+			Author:    "author" + strconv.Itoa(i),
+			Title:     "Post " + strconv.Itoa(i),
+			Score:     10,
+			CreatedAt: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		}
+	}
+	return items
+}
+
+// Returns a Builder that does not touch the network
+func testBuilder() *Builder {
+	b := NewBuilder()
+	b.IconFallback = nil
+	b.Workers = 2
+	return b
+}
+
+// Returns the names of the Topics of a Nup, in order
+func topicNames(t *testing.T, n *libwara.Nup) []string {
+	t.Helper()
+	names := []string{}
+	for _, h := range n.TopicHandles() {
+		topic, err := n.Topic(h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, topic.Name)
+	}
+	return names
+}
+
+func TestAddTopic(t *testing.T) {
+	b := testBuilder()
+	n := libwara.InitNup()
+
+	if err := b.AddTopic(context.Background(), n, TopicConfig{Name: "test"}, testItems(3)); err != nil {
+		t.Fatal(err)
+	}
+	handles := n.TopicHandles()
+	if len(handles) != 1 {
+		t.Fatalf("got %d Topics", len(handles))
+	}
+	posts, err := n.PostHandles(handles[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(posts) != 3 {
+		t.Fatalf("got %d Posts", len(posts))
+	}
+	post, err := n.Post(posts[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if post.Body != "Post 0" || post.ScreenName != "author0" {
+		t.Errorf("got %+v", post)
+	}
+}
+
+func TestAddTopicRollback(t *testing.T) {
+	b := testBuilder()
+	history, err := OpenHistory(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer history.Close()
+	b.History = history
+
+	n := libwara.InitNup()
+	if _, err := n.NewTopic("keep"); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := b.AddTopic(ctx, n, TopicConfig{Name: "test"}, testItems(3)); err == nil {
+		t.Fatal("no error for a cancelled build")
+	}
+	if names := topicNames(t, n); len(names) != 1 || names[0] != "keep" {
+		t.Errorf("got Topics %v, the cancelled Topic should have been removed", names)
+	}
+	if _, used, err := history.LastUsed("t3_0"); err != nil || used {
+		t.Errorf("Items of the cancelled Topic were recorded as used, %v", err)
+	}
+
+	// The limits of the Nup are as they were, so the Topic can be built again
+	if err := b.AddTopic(context.Background(), n, TopicConfig{Name: "test"}, testItems(3)); err != nil {
+		t.Fatal(err)
+	}
+	if names := topicNames(t, n); len(names) != 2 || names[1] != "test" {
+		t.Errorf("got Topics %v", names)
+	}
+	if _, used, err := history.LastUsed("t3_0"); err != nil || !used {
+		t.Errorf("Items of the built Topic were not recorded as used, %v", err)
+	}
+}
+
+func TestAddTopicStats(t *testing.T) {
+	b := testBuilder()
+	n := libwara.InitNup()
+
+	// Only the first MAX_PEOPLE_PER_TOPIC authors fit, the rest must not be counted
+	items := testItems(int(libwara.MAX_PEOPLE_PER_TOPIC) + 10)
+	if err := b.AddTopic(context.Background(), n, TopicConfig{Name: "test"}, items); err != nil {
+		t.Fatal(err)
+	}
+	topic, err := n.Topic(n.TopicHandles()[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if topic.ParticipantCount != libwara.MAX_PEOPLE_PER_TOPIC {
+		t.Errorf("got %d participants, want %d", topic.ParticipantCount, libwara.MAX_PEOPLE_PER_TOPIC)
+	}
+	if want := b.Stats.EmpathyCount(10 * int(libwara.MAX_PEOPLE_PER_TOPIC)); topic.EmpathyCount != want {
+		t.Errorf("got empathy %d, want %d", topic.EmpathyCount, want)
+	}
+}
+
+func TestAddTopicSortByScore(t *testing.T) {
+	b := testBuilder()
+	n := libwara.InitNup()
+
+	items := testItems(3)
+	for i, score := range []int{5, 500, 50} {
+		items[i].Score = score
+	}
+	if err := b.AddTopic(context.Background(), n, TopicConfig{Name: "test"}, items); err != nil {
+		t.Fatal(err)
+	}
+	topic := n.TopicHandles()[0]
+	if err := n.SortPosts(topic, libwara.SortByScore); err != nil {
+		t.Fatal(err)
+	}
+
+	posts, err := n.PostHandles(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []int{1, 2, 0} {
+		post, err := n.Post(posts[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if post.ScreenName != items[want].Author {
+			t.Errorf("Post %d is by %s, want %s", i, post.ScreenName, items[want].Author)
+		}
+		if empathy := strconv.Itoa(b.Stats.EmpathyCount(items[want].Score)); post.EmpathyCount != empathy {
+			t.Errorf("Post %d has empathy %q, want %q", i, post.EmpathyCount, empathy)
+		}
+	}
+}
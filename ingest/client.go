@@ -0,0 +1,266 @@
+package ingest
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultUserAgent = "RedditToWara/2 (WaraWara Plaza 1stNUP generator)"
+
+// Reads Reddit's public JSON API
+type Client struct {
+	BaseURL   string        // Reddit API root, "https://www.reddit.com" unless pointed at a test server
+	HTTP      *http.Client  // Client used for requests, http.DefaultClient if nil
+	UserAgent string        // Reddit rejects requests without a descriptive user agent
+	CacheDir  string        // Directory responses are cached in, caching is off if blank
+	CacheTTL  time.Duration // How long cached responses are used for
+
+	OAuth         *OAuthConfig  // Script app credentials, requests are unauthenticated if nil
+	MaxRetries    int           // How often a request that got a 429 or 5xx is tried again
+	RetryDelay    time.Duration // Delay before the first retry, doubled for every retry after it
+	MaxRetryDelay time.Duration // Cap on the delay between retries, no cap if 0
+
+	token oauthToken
+	limit rateLimit
+
+	// Swapped out by tests so they do not have to wait for real time to pass
+	nowFunc   func() time.Time
+	sleepFunc func(ctx context.Context, d time.Duration) error
+}
+
+// Creates a Client for reddit.com
+func NewClient() *Client {
+	return &Client{
+		BaseURL:       "https://www.reddit.com",
+		UserAgent:     defaultUserAgent,
+		CacheTTL:      time.Hour,
+		MaxRetries:    4,
+		RetryDelay:    time.Second,
+		MaxRetryDelay: time.Minute,
+	}
+}
+
+func (c *Client) now() time.Time {
+	if c.nowFunc == nil {
+		return time.Now()
+	}
+	return c.nowFunc()
+}
+
+func (c *Client) sleep(ctx context.Context, d time.Duration) error {
+	if c.sleepFunc == nil {
+		return sleepContext(ctx, d)
+	}
+	return c.sleepFunc(ctx, d)
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTP == nil {
+		return http.DefaultClient
+	}
+	return c.HTTP
+}
+
+// Returns the cache file for a URL
+func (c *Client) cachePath(rawURL string) string {
+	sum := sha1.Sum([]byte(rawURL))
+	return filepath.Join(c.CacheDir, hex.EncodeToString(sum[:]))
+}
+
+// Returns a cached response if there is one that has not expired
+func (c *Client) cached(rawURL string) ([]byte, bool) {
+	if c.CacheDir == "" {
+		return nil, false
+	}
+	path := c.cachePath(rawURL)
+	info, err := os.Stat(path)
+	if err != nil || time.Since(info.ModTime()) > c.CacheTTL {
+		return nil, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// Stores a response in the cache
+func (c *Client) store(rawURL string, data []byte) error {
+	if c.CacheDir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(c.CacheDir, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(c.cachePath(rawURL), data, 0o644)
+}
+
+// Fetches a URL, going through the cache
+func (c *Client) fetch(ctx context.Context, rawURL string) ([]byte, error) {
+	return c.fetchAs(ctx, rawURL, "")
+}
+
+// Fetches a URL asking for a media type, going through the cache
+func (c *Client) fetchAs(ctx context.Context, rawURL string, accept string) ([]byte, error) {
+	if data, ok := c.cached(rawURL); ok {
+		return data, nil
+	}
+
+	// Only requests to the API count against the rate limit and carry the access token
+	api := strings.HasPrefix(rawURL, c.BaseURL)
+	refreshed := false
+
+	for attempt := 0; ; attempt++ {
+		if api {
+			if err := c.waitRateLimit(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := c.do(ctx, rawURL, accept, api)
+		if err != nil {
+			return nil, err
+		}
+		if api {
+			c.updateRateLimit(resp.Header)
+		}
+
+		switch {
+		case resp.StatusCode == http.StatusOK:
+			data, err := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err != nil {
+				return nil, err
+			}
+			// Failing to cache is not an error, the response is just fetched again next time
+			c.store(rawURL, data)
+			return data, nil
+
+		case resp.StatusCode == http.StatusUnauthorized && api && c.OAuth != nil && !refreshed:
+			// The token was revoked or expired early, get a new one and try again straight away
+			resp.Body.Close()
+			c.dropToken()
+			refreshed = true
+			attempt--
+
+		case retryable(resp.StatusCode) && attempt < c.MaxRetries:
+			resp.Body.Close()
+			if err := c.sleep(ctx, c.backoff(attempt, resp)); err != nil {
+				return nil, err
+			}
+
+		default:
+			resp.Body.Close()
+			return nil, errors.New("GET " + rawURL + ": " + resp.Status)
+		}
+	}
+}
+
+// Sends a single GET request
+func (c *Client) do(ctx context.Context, rawURL string, accept string, api bool) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", c.UserAgent)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+
+	if api && c.OAuth != nil {
+		token, err := c.accessToken(ctx)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "bearer "+token)
+	}
+
+	return c.httpClient().Do(req)
+}
+
+// Fetches and decodes a JSON document from a path relative to BaseURL
+func (c *Client) getJSON(ctx context.Context, path string, query url.Values, v interface{}) error {
+	rawURL := c.BaseURL + path
+	if len(query) > 0 {
+		rawURL += "?" + query.Encode()
+	}
+
+	data, err := c.fetch(ctx, rawURL)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// Returns up to limit submissions from a subreddit, sorted by sort ("hot", "new", "top", ...)
+func (c *Client) Submissions(ctx context.Context, subreddit, sort string, limit int) ([]Submission, error) {
+	query := url.Values{}
+	query.Set("limit", strconv.Itoa(limit))
+	query.Set("raw_json", "1")
+
+	l := Listing{}
+	if err := c.getJSON(ctx, "/r/"+url.PathEscape(subreddit)+"/"+sort+".json", query, &l); err != nil {
+		return nil, err
+	}
+
+	return l.Submissions(), nil
+}
+
+// Returns the about page of a subreddit
+func (c *Client) About(ctx context.Context, subreddit string) (*SubredditAbout, error) {
+	query := url.Values{}
+	query.Set("raw_json", "1")
+
+	thing := struct {
+		Kind string         `json:"kind"`
+		Data SubredditAbout `json:"data"`
+	}{}
+	if err := c.getJSON(ctx, "/r/"+url.PathEscape(subreddit)+"/about.json", query, &thing); err != nil {
+		return nil, err
+	}
+	if thing.Kind != "t5" {
+		return nil, errors.New("no subreddit " + subreddit)
+	}
+
+	return &thing.Data, nil
+}
+
+// Downloads a file into the cache directory, or a temporary file when caching is off or fails, and returns its path
+// Temporary files are removed by the caller
+func (c *Client) download(ctx context.Context, rawURL string) (path string, temporary bool, err error) {
+	data, err := c.fetch(ctx, rawURL)
+	if err != nil {
+		return "", false, err
+	}
+	if c.CacheDir != "" {
+		// The cache file may be missing or left over from an earlier download if storing failed
+		path := c.cachePath(rawURL)
+		if cached, err := os.ReadFile(path); err == nil && bytes.Equal(cached, data) {
+			return path, false, nil
+		}
+	}
+
+	f, err := os.CreateTemp("", "reddittowara-*")
+	if err != nil {
+		return "", false, err
+	}
+	defer f.Close()
+	if _, err = f.Write(data); err != nil {
+		os.Remove(f.Name())
+		return "", false, err
+	}
+
+	return f.Name(), true, nil
+}
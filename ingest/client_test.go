@@ -0,0 +1,394 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// A clock that only moves when the client sleeps
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func (f *fakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *fakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sleeps = append(f.sleeps, d)
+	f.now = f.now.Add(d)
+	return nil
+}
+
+// Returns a Client pointed at a test server, using a fake clock
+func testClient(t *testing.T, handler http.Handler) (*Client, *fakeClock) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	clock := &fakeClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	c := NewClient()
+	c.BaseURL = server.URL
+	c.HTTP = server.Client()
+	c.nowFunc = clock.Now
+	c.sleepFunc = clock.Sleep
+	return c, clock
+}
+
+const listingJSON = `{"kind":"Listing","data":{"children":[{"kind":"t3","data":{"id":"abc","title":"Hello","num_comments":3}}]}}`
+
+func TestClientSubmissions(t *testing.T) {
+	c, _ := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/r/wiiu/hot.json" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("limit") != "5" || r.URL.Query().Get("raw_json") != "1" {
+			t.Errorf("got query %q", r.URL.RawQuery)
+		}
+		if r.Header.Get("User-Agent") != defaultUserAgent {
+			t.Errorf("got user agent %q", r.Header.Get("User-Agent"))
+		}
+		w.Write([]byte(listingJSON))
+	}))
+
+	subs, err := c.Submissions(context.Background(), "wiiu", "hot", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subs) != 1 || subs[0].Id != "abc" || subs[0].NumComments != 3 {
+		t.Errorf("got %+v", subs)
+	}
+}
+
+func TestClientCache(t *testing.T) {
+	requests := 0
+	c, _ := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(listingJSON))
+	}))
+	c.CacheDir = t.TempDir()
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.Submissions(context.Background(), "wiiu", "hot", 5); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("made %d requests, the second should have been cached", requests)
+	}
+}
+
+func TestClientRetry(t *testing.T) {
+	requests := 0
+	c, clock := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch requests {
+		case 1:
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 3:
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			w.Write([]byte(listingJSON))
+		}
+	}))
+	c.RetryDelay = time.Second
+	c.MaxRetryDelay = 3 * time.Second
+
+	if _, err := c.Submissions(context.Background(), "wiiu", "hot", 5); err != nil {
+		t.Fatal(err)
+	}
+	// Retry-After, then the doubled delay, then the capped delay
+	want := []time.Duration{7 * time.Second, 2 * time.Second, 3 * time.Second}
+	if len(clock.sleeps) != len(want) {
+		t.Fatalf("slept %v, want %v", clock.sleeps, want)
+	}
+	for i := range want {
+		if clock.sleeps[i] != want[i] {
+			t.Errorf("slept %v, want %v", clock.sleeps, want)
+			break
+		}
+	}
+}
+
+func TestClientRetryGivesUp(t *testing.T) {
+	requests := 0
+	c, clock := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	c.MaxRetries = 2
+
+	if _, err := c.Submissions(context.Background(), "wiiu", "hot", 5); err == nil {
+		t.Fatal("no error after running out of retries")
+	}
+	if requests != 3 || len(clock.sleeps) != 2 {
+		t.Errorf("made %d requests and slept %d times", requests, len(clock.sleeps))
+	}
+
+	requests = 0
+	c.sleepFunc = func(ctx context.Context, d time.Duration) error { return context.Canceled }
+	if _, err := c.Submissions(context.Background(), "wiiu", "hot", 5); !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want the error of the cancelled sleep", err)
+	}
+	if requests != 1 {
+		t.Errorf("made %d requests after the sleep was cancelled", requests)
+	}
+}
+
+func TestClientNotRetried(t *testing.T) {
+	requests := 0
+	c, clock := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.NotFound(w, r)
+	}))
+
+	if _, err := c.About(context.Background(), "gone"); err == nil {
+		t.Fatal("no error for a 404")
+	}
+	if requests != 1 || len(clock.sleeps) != 0 {
+		t.Errorf("made %d requests and slept %d times for a 404", requests, len(clock.sleeps))
+	}
+}
+
+func TestClientRateLimit(t *testing.T) {
+	requests := 0
+	c, clock := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		// One request left on the first response, none on the second
+		w.Header().Set("X-Ratelimit-Remaining", strconv.Itoa(2-requests))
+		w.Header().Set("X-Ratelimit-Reset", "30")
+		w.Write([]byte(listingJSON))
+	}))
+
+	for i := 0; i < 3; i++ {
+		if _, err := c.Submissions(context.Background(), "wiiu", "hot", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(clock.sleeps) != 1 || clock.sleeps[0] != 30*time.Second {
+		t.Errorf("slept %v, want one wait for the reset", clock.sleeps)
+	}
+
+	// Requests outside the API do not wait for or update the rate limit
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Ratelimit-Remaining", "0")
+		w.Header().Set("X-Ratelimit-Reset", "600")
+	}))
+	defer other.Close()
+	reset := c.limit.reset
+	if _, err := c.fetch(context.Background(), other.URL+"/image.png"); err != nil {
+		t.Fatal(err)
+	}
+	if !c.limit.reset.Equal(reset) || len(clock.sleeps) != 1 {
+		t.Error("a request outside the API used the rate limit")
+	}
+}
+
+func TestClientRateLimitConcurrent(t *testing.T) {
+	const callers = 4
+	var mu sync.Mutex
+	requests := 0
+	c, _ := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		first := requests == 1
+		mu.Unlock()
+		// The first response uses up the limit, the ones after the reset restore it
+		if first {
+			w.Header().Set("X-Ratelimit-Remaining", "0")
+		} else {
+			w.Header().Set("X-Ratelimit-Remaining", "100")
+		}
+		w.Header().Set("X-Ratelimit-Reset", "30")
+		w.Write([]byte(listingJSON))
+	}))
+	if _, err := c.Submissions(context.Background(), "wiiu", "hot", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	// Sleeping callers are held until every caller is waiting, so none of them can see a restored limit
+	slept := make(chan time.Duration, callers)
+	release := make(chan struct{})
+	c.sleepFunc = func(ctx context.Context, d time.Duration) error {
+		slept <- d
+		<-release
+		return nil
+	}
+
+	errs := make(chan error, callers)
+	for i := 0; i < callers; i++ {
+		go func() {
+			_, err := c.Submissions(context.Background(), "wiiu", "hot", 1)
+			errs <- err
+		}()
+	}
+	timeout := time.After(5 * time.Second)
+	for i := 0; i < callers; i++ {
+		select {
+		case d := <-slept:
+			if d != 30*time.Second {
+				t.Errorf("slept %v, want the time until the reset", d)
+			}
+		case <-timeout:
+			t.Errorf("only %d of %d callers waited for the reset", i, callers)
+			i = callers
+		}
+	}
+	close(release)
+	for i := 0; i < callers; i++ {
+		if err := <-errs; err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+// Returns an OAuth token endpoint and the API behind it
+// The API answers 401 to any token but the latest one
+func oauthServer(t *testing.T, expiresIn int) (http.Handler, *int) {
+	t.Helper()
+	tokens := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/access_token", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("token requested with %s", r.Method)
+		}
+		id, secret, ok := r.BasicAuth()
+		if !ok || id != "id" || secret != "secret" {
+			t.Errorf("got basic auth %q %q", id, secret)
+		}
+		r.ParseForm()
+		if r.PostForm.Get("grant_type") != "password" || r.PostForm.Get("username") != "user" || r.PostForm.Get("password") != "pass" {
+			t.Errorf("got form %v", r.PostForm)
+		}
+		tokens++
+		w.Write([]byte(`{"access_token":"token` + strconv.Itoa(tokens) + `","token_type":"bearer","expires_in":` + strconv.Itoa(expiresIn) + `}`))
+	})
+	mux.HandleFunc("/r/wiiu/hot.json", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "bearer token"+strconv.Itoa(tokens) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(listingJSON))
+	})
+	return mux, &tokens
+}
+
+// Points a test Client at the OAuth endpoint of its test server
+func withOAuth(c *Client) {
+	c.OAuth = &OAuthConfig{
+		ClientId:     "id",
+		ClientSecret: "secret",
+		Username:     "user",
+		Password:     "pass",
+		TokenURL:     c.BaseURL + "/api/v1/access_token",
+	}
+}
+
+func TestClientOAuthToken(t *testing.T) {
+	handler, tokens := oauthServer(t, 3600)
+	c, clock := testClient(t, handler)
+	withOAuth(c)
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.Submissions(context.Background(), "wiiu", "hot", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if *tokens != 1 {
+		t.Errorf("requested %d tokens, the first should have been reused", *tokens)
+	}
+
+	// A token about to expire is replaced before it is used
+	clock.now = clock.now.Add(3600*time.Second - 30*time.Second)
+	if _, err := c.Submissions(context.Background(), "wiiu", "hot", 3); err != nil {
+		t.Fatal(err)
+	}
+	if *tokens != 2 {
+		t.Errorf("requested %d tokens, the expiring one should have been replaced", *tokens)
+	}
+}
+
+func TestClientOAuthRefresh(t *testing.T) {
+	handler, tokens := oauthServer(t, 3600)
+	c, clock := testClient(t, handler)
+	withOAuth(c)
+
+	if _, err := c.Submissions(context.Background(), "wiiu", "hot", 1); err != nil {
+		t.Fatal(err)
+	}
+	// Revoke the token behind the client's back
+	*tokens++
+
+	if _, err := c.Submissions(context.Background(), "wiiu", "hot", 2); err != nil {
+		t.Fatal(err)
+	}
+	if *tokens != 3 {
+		t.Errorf("token count is %d, the 401 should have requested a new one", *tokens)
+	}
+	if len(clock.sleeps) != 0 {
+		t.Errorf("slept %v before refreshing the token", clock.sleeps)
+	}
+}
+
+func TestClientOAuthRefreshOnce(t *testing.T) {
+	tokens := 0
+	c, _ := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/access_token" {
+			tokens++
+			w.Write([]byte(`{"access_token":"token","expires_in":3600}`))
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	withOAuth(c)
+
+	if _, err := c.Submissions(context.Background(), "wiiu", "hot", 1); err == nil {
+		t.Fatal("no error when every token is rejected")
+	}
+	if tokens != 2 {
+		t.Errorf("requested %d tokens, want one refresh", tokens)
+	}
+}
+
+func TestClientOAuthErrors(t *testing.T) {
+	for _, body := range []string{
+		`{"error":"invalid_grant"}`,
+		`{"token_type":"bearer"}`,
+		`not json`,
+	} {
+		c, _ := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(body))
+		}))
+		withOAuth(c)
+		if _, err := c.Submissions(context.Background(), "wiiu", "hot", 1); err == nil {
+			t.Errorf("no error for token response %s", body)
+		}
+	}
+
+	c, _ := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	withOAuth(c)
+	if _, err := c.Submissions(context.Background(), "wiiu", "hot", 1); err == nil {
+		t.Error("no error when the token endpoint fails")
+	}
+}
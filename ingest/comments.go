@@ -0,0 +1,134 @@
+package ingest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func (l *commentListing) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte(`""`)) {
+		return nil
+	}
+	type plain commentListing
+	return json.Unmarshal(data, (*plain)(l))
+}
+
+// Returns every comment in the listing and the replies below them, depth first
+func (l *commentListing) flatten() []Comment {
+	ret := []Comment{}
+	for _, c := range l.Data.Children {
+		if c.Kind != "t1" {
+			continue
+		}
+		ret = append(ret, c.Data)
+		ret = append(ret, c.Data.Replies.flatten()...)
+	}
+	return ret
+}
+
+// Returns the comment tree of a submission, flattened depth first
+// id may be the bare ID or the fullname of the submission
+func (c *Client) Comments(ctx context.Context, id string, limit int) ([]Comment, error) {
+	query := url.Values{}
+	query.Set("limit", strconv.Itoa(limit))
+	query.Set("sort", "top")
+	query.Set("raw_json", "1")
+
+	// The first listing holds the submission, the second the comments
+	listings := []commentListing{}
+	if err := c.getJSON(ctx, "/comments/"+url.PathEscape(strings.TrimPrefix(id, "t3_"))+".json", query, &listings); err != nil {
+		return nil, err
+	}
+	if len(listings) < 2 {
+		return []Comment{}, nil
+	}
+
+	return listings[1].flatten(), nil
+}
+
+// Converts a Comment to an Item
+func (c *Comment) Item() Item {
+	return Item{
+		Id:        c.Name,
+		ParentId:  c.ParentId,
+		Author:    c.Author,
+		Body:      c.Body,
+		Score:     c.Score,
+		Comments:  len(c.Replies.flatten()),
+		CreatedAt: c.CreatedUtc.Time(),
+	}
+}
+
+// Checks if a comment has been deleted or removed
+func (c *Comment) deleted() bool {
+	return c.Author == "[deleted]" || c.Body == "[deleted]" || c.Body == "[removed]"
+}
+
+// Returns the top n comments of an Item by score as Items
+func (c *Client) topComments(ctx context.Context, item *Item, n int) ([]Item, error) {
+	comments, err := c.Comments(ctx, item.Id, n*4)
+	if err != nil {
+		return nil, err
+	}
+
+	kept := make([]Comment, 0, len(comments))
+	for i := range comments {
+		if !comments[i].deleted() {
+			kept = append(kept, comments[i])
+		}
+	}
+	sort.SliceStable(kept, func(i, j int) bool {
+		return kept[i].Score > kept[j].Score
+	})
+	if len(kept) > n {
+		kept = kept[:n]
+	}
+
+	ret := make([]Item, len(kept))
+	for i := range kept {
+		ret[i] = kept[i].Item()
+	}
+
+	return ret, nil
+}
+
+// Follows every Item that has comments with its top b.CommentsPerItem comments
+// The comment count of each Item is left as Reddit reports it, since only some comments are fetched
+// Comments are fetched in parallel, but each Item is still followed by its own comments
+// Items whose comments cannot be fetched are kept without them
+func (b *Builder) expandComments(ctx context.Context, items []Item) ([]Item, error) {
+	if b.Client == nil || b.CommentsPerItem <= 0 {
+		return items, nil
+	}
+
+	expanded := make([][]Item, len(items))
+	err := b.parallel(ctx, len(items), func(ctx context.Context, i int) {
+		item := items[i]
+		expanded[i] = []Item{item}
+		if item.Comments == 0 || item.ParentId != "" {
+			return
+		}
+
+		comments, err := b.Client.topComments(ctx, &item, b.CommentsPerItem)
+		if err != nil {
+			log.Println("could not fetch comments for " + item.Id + ": " + err.Error())
+			return
+		}
+		expanded[i] = append(expanded[i], comments...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]Item, 0, len(items))
+	for i := range expanded {
+		ret = append(ret, expanded[i]...)
+	}
+	return ret, nil
+}
@@ -0,0 +1,129 @@
+package ingest
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"cornchip.com/libwara/v2"
+)
+
+// Configuration of a single Topic
+type TopicConfig struct {
+	Name      string `json:"name"`               // Name of the Topic, ignored when Title is set
+	Subreddit string `json:"subreddit"`          // Subreddit the Posts come from
+	Feed      string `json:"feed,omitempty"`     // RSS or Atom feed the Posts come from instead
+	Outbox    string `json:"outbox,omitempty"`   // ActivityPub outbox the Posts come from instead
+	Dir       string `json:"dir,omitempty"`      // Directory of Markdown files the Posts come from instead
+	Title     string `json:"title,omitempty"`    // Game from the title catalog, the Topic is named after it
+	Country   string `json:"country,omitempty"`  // ISO 3166-1 alpha-2 code, defaults to the Config country
+	Region    string `json:"region,omitempty"`   // JPN, USA, EUR or ALL, defaults to the region of the country
+	Language  string `json:"language,omitempty"` // ISO 639-1 code, detected from each Post when blank
+}
+
+// Configuration of a whole Nup
+type Config struct {
+	Country  string            `json:"country"`  // ISO 3166-1 alpha-2 code used by Topics without one
+	Dither   string            `json:"dither"`   // How image posts are turned into paintings, see libwara.DitherByName
+	Comments int               `json:"comments"` // Top comments of each submission that become Posts of their own
+	Workers  int               `json:"workers"`  // Posts built at the same time, defaults to one per CPU
+	OAuth    *OAuthConfig      `json:"oauth"`    // Script app credentials, Reddit is read without logging in if missing
+	Dump     *DumpConfig       `json:"dump"`     // Read submissions from a dump instead of Reddit if set
+	History  string            `json:"history"`  // File remembering the Posts of earlier builds, so new builds prefer fresh ones
+	Miis     map[string]string `json:"miis"`     // Author to a Mii QR code image, for authors who want their own Mii
+	Limits   libwara.Limits    `json:"limits"`   // Caps on the size of the Nup, fields left out or 0 keep libwara.DefaultLimits
+	Topics   []TopicConfig     `json:"topics"`
+}
+
+// Configuration for building from a Pushshift style dump
+type DumpConfig struct {
+	Path     string `json:"path"`      // NDJSON file, optionally zstd compressed
+	After    string `json:"after"`     // Keep submissions posted on or after this date (YYYY-MM-DD)
+	Before   string `json:"before"`    // Keep submissions posted before this date (YYYY-MM-DD)
+	MinScore *int   `json:"min_score"` // Keep submissions scoring at least this
+}
+
+// Returns the filter selecting the submissions of every configured subreddit
+func (c *Config) DumpFilter() (DumpFilter, error) {
+	f := DumpFilter{}
+	if c.Dump == nil {
+		return f, nil
+	}
+
+	for _, t := range c.Topics {
+		f.Subreddits = append(f.Subreddits, t.Subreddit)
+	}
+	f.MinScore = c.Dump.MinScore
+
+	var err error
+	if c.Dump.After != "" {
+		if f.After, err = time.Parse("2006-01-02", c.Dump.After); err != nil {
+			return f, err
+		}
+	}
+	if c.Dump.Before != "" {
+		if f.Before, err = time.Parse("2006-01-02", c.Dump.Before); err != nil {
+			return f, err
+		}
+	}
+
+	return f, nil
+}
+
+// TopicConfig with every code turned into a Nintendo ID
+type topicSettings struct {
+	country  libwara.CountryId
+	region   libwara.Region
+	regionId libwara.RegionId
+	language *libwara.LanguageId
+}
+
+// Reads a Config from a JSON file
+// Topics without a country get the country of the Config
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Config{Country: "US", Limits: libwara.DefaultLimits}
+	if err = json.Unmarshal(data, c); err != nil {
+		return nil, err
+	}
+	for i := range c.Topics {
+		if c.Topics[i].Country == "" {
+			c.Topics[i].Country = c.Country
+		}
+	}
+	return c, nil
+}
+
+// Turns the codes of a TopicConfig into Nintendo IDs
+func (t *TopicConfig) settings() (topicSettings, error) {
+	s := topicSettings{country: libwara.CountryUnitedStates}
+
+	var err error
+	if t.Country != "" {
+		if s.country, err = libwara.CountryByCode(t.Country); err != nil {
+			return s, err
+		}
+	}
+
+	s.region = s.country.Region()
+	if t.Region != "" {
+		s.region = libwara.Region(t.Region)
+	}
+	if s.regionId, err = s.region.Id(); err != nil {
+		return s, err
+	}
+
+	if t.Language != "" {
+		lang, err := libwara.LanguageByCode(t.Language)
+		if err != nil {
+			return s, err
+		}
+		s.language = &lang
+	}
+
+	return s, nil
+}
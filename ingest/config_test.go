@@ -0,0 +1,69 @@
+package ingest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"cornchip.com/libwara/v2"
+)
+
+func TestLoadConfigLimits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	write := func(text string) {
+		if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(`{"topics": []}`)
+	c, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Limits != libwara.DefaultLimits {
+		t.Errorf("got %+v without a limits key, want the defaults", c.Limits)
+	}
+
+	write(`{"limits": {"max_posts_per_topic": 50, "max_people_per_topic": 40}}`)
+	c, err = LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := libwara.DefaultLimits
+	want.MaxPostsPerTopic = 50
+	want.MaxPeoplePerTopic = 40
+	if c.Limits != want {
+		t.Errorf("got %+v, want %+v", c.Limits, want)
+	}
+
+	n := libwara.InitNup()
+	if err := n.SetLimits(c.Limits); err != nil {
+		t.Fatal(err)
+	}
+	if n.Limits() != want {
+		t.Errorf("the Nup enforces %+v", n.Limits())
+	}
+}
+
+func TestConfigSourceLimit(t *testing.T) {
+	for _, test := range []struct {
+		limits  libwara.Limits
+		history string
+		want    int
+	}{
+		{libwara.Limits{}, "", int(libwara.MAX_POSTS_PER_TOPIC)},
+		{libwara.Limits{MaxPostsPerTopic: 50}, "", 50},
+		{libwara.Limits{MaxPostsPerTopic: 50}, "history.db", historyFetchLimit},
+		{libwara.Limits{MaxPostsPerTopic: 150}, "history.db", 150},
+	} {
+		c := &Config{Limits: test.limits, History: test.history}
+		src, err := c.Source(NewClient())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := src.(*configSource).subreddit.(*RedditSource).Limit; got != test.want {
+			t.Errorf("%+v with history %q: got %d submissions, want %d", test.limits, test.history, got, test.want)
+		}
+	}
+}
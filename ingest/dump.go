@@ -0,0 +1,169 @@
+package ingest
+
+import (
+	"bufio"
+	"bytes"
+	"container/heap"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Magic number at the start of a zstd frame
+var zstdMagic = []byte{0x28, 0xB5, 0x2F, 0xFD}
+
+// Pushshift dumps are compressed with a 2 GiB window
+const dumpMaxWindow = 1 << 31
+
+// Which submissions of a dump are kept
+type DumpFilter struct {
+	Subreddits []string  // Subreddits to keep, ignoring case. Every subreddit is kept if empty
+	After      time.Time // Keep submissions posted at or after this time, ignored if zero
+	Before     time.Time // Keep submissions posted before this time, ignored if zero
+	MinScore   *int      // Keep submissions scoring at least this, nil disables the check
+}
+
+// Checks if a submission passes the filter
+func (f *DumpFilter) keep(s *Submission) bool {
+	if len(f.Subreddits) > 0 {
+		found := false
+		for _, sub := range f.Subreddits {
+			if strings.EqualFold(sub, s.Subreddit) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	created := s.CreatedUtc.Time()
+	if !f.After.IsZero() && created.Before(f.After) {
+		return false
+	}
+	if !f.Before.IsZero() && !created.Before(f.Before) {
+		return false
+	}
+	if f.MinScore != nil && s.Score < *f.MinScore {
+		return false
+	}
+
+	return true
+}
+
+// Opens a Pushshift style dump, decompressing it on the fly if it is zstd compressed
+func OpenDump(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r := bufio.NewReader(f)
+	magic, err := r.Peek(len(zstdMagic))
+	if err != nil && err != io.EOF {
+		f.Close()
+		return nil, err
+	}
+	if !bytes.Equal(magic, zstdMagic) {
+		return struct {
+			io.Reader
+			io.Closer
+		}{r, f}, nil
+	}
+
+	z, err := zstd.NewReader(r, zstd.WithDecoderMaxWindow(dumpMaxWindow), zstd.WithDecoderLowmem(true))
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &zstdDump{z, f}, nil
+}
+
+// A zstd decoder and the file it reads from
+type zstdDump struct {
+	*zstd.Decoder
+	file *os.File
+}
+
+func (z *zstdDump) Close() error {
+	z.Decoder.Close()
+	return z.file.Close()
+}
+
+// Reads a newline delimited JSON dump of submissions one line at a time, calling fn for every submission
+// that passes the filter. Lines that are not valid submissions are skipped
+func ReadDump(r io.Reader, filter DumpFilter, fn func(s *Submission) error) error {
+	br := bufio.NewReaderSize(r, 1<<20)
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			s := Submission{}
+			if jsonErr := json.Unmarshal(line, &s); jsonErr == nil && filter.keep(&s) {
+				if fnErr := fn(&s); fnErr != nil {
+					return fnErr
+				}
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Reads a dump and returns the best scoring perSubreddit Items of each subreddit, best first
+// Only perSubreddit Items per subreddit are held in memory, so dumps of any size can be read
+func CollectDump(r io.Reader, filter DumpFilter, perSubreddit int) (map[string][]Item, error) {
+	best := map[string]*itemHeap{}
+	err := ReadDump(r, filter, func(s *Submission) error {
+		key := strings.ToLower(s.Subreddit)
+		h, ok := best[key]
+		if !ok {
+			h = &itemHeap{}
+			best[key] = h
+		}
+
+		item := s.Item()
+		if h.Len() < perSubreddit {
+			heap.Push(h, item)
+		} else if h.Len() > 0 && (*h)[0].Score < item.Score {
+			(*h)[0] = item
+			heap.Fix(h, 0)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ret := map[string][]Item{}
+	for key, h := range best {
+		items := make([]Item, h.Len())
+		for i := len(items) - 1; i >= 0; i-- {
+			items[i] = heap.Pop(h).(Item)
+		}
+		ret[key] = items
+	}
+	return ret, nil
+}
+
+// Min heap of Items by score, so the worst Item is the one replaced
+type itemHeap []Item
+
+func (h itemHeap) Len() int            { return len(h) }
+func (h itemHeap) Less(i, j int) bool  { return h[i].Score < h[j].Score }
+func (h itemHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *itemHeap) Push(x interface{}) { *h = append(*h, x.(Item)) }
+func (h *itemHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
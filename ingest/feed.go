@@ -0,0 +1,186 @@
+package ingest
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// Reads a Topic from an RSS 2.0 or Atom feed, one Item per entry
+type FeedSource struct {
+	Client    *Client
+	TopicList []TopicConfig // Topics with their Feed set
+}
+
+// The parts of an RSS 2.0 document that become Items
+type rssFeed struct {
+	Channel struct {
+		Title string `xml:"title"`
+		Items []struct {
+			Title       string `xml:"title"`
+			Link        string `xml:"link"`
+			Description string `xml:"description"`
+			Content     string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+			Author      string `xml:"author"`
+			Creator     string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+			Guid        string `xml:"guid"`
+			PubDate     string `xml:"pubDate"`
+			Category    string `xml:"category"`
+		} `xml:"item"`
+	} `xml:"channel"`
+}
+
+// The parts of an Atom document that become Items
+type atomFeed struct {
+	Title   string     `xml:"title"`
+	Author  atomAuthor `xml:"author"`
+	Entries []struct {
+		Id        string       `xml:"id"`
+		Title     string       `xml:"title"`
+		Summary   string       `xml:"summary"`
+		Content   string       `xml:"content"`
+		Authors   []atomAuthor `xml:"author"`
+		Published string       `xml:"published"`
+		Updated   string       `xml:"updated"`
+		Category  struct {
+			Term string `xml:"term,attr"`
+		} `xml:"category"`
+	} `xml:"entry"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+func (s *FeedSource) Topics(ctx context.Context) ([]TopicConfig, error) {
+	return s.TopicList, nil
+}
+
+func (s *FeedSource) Posts(ctx context.Context, topic TopicConfig) ([]Item, error) {
+	data, err := s.Client.fetch(ctx, topic.Feed)
+	if err != nil {
+		return nil, err
+	}
+	return ParseFeed(data)
+}
+
+// Parses an RSS 2.0 or Atom feed into Items
+func ParseFeed(data []byte) ([]Item, error) {
+	root, err := rootElement(data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch root {
+	case "rss":
+		return parseRSS(data)
+	case "feed":
+		return parseAtom(data)
+	}
+	return nil, errors.New("not an RSS or Atom feed, root element is " + root)
+}
+
+// Returns the name of the root element of an XML document
+func rootElement(data []byte) (string, error) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return "", err
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
+
+func parseRSS(data []byte) ([]Item, error) {
+	feed := rssFeed{}
+	if err := xml.Unmarshal(data, &feed); err != nil {
+		return nil, err
+	}
+
+	ret := make([]Item, 0, len(feed.Channel.Items))
+	for _, e := range feed.Channel.Items {
+		author := e.Creator
+		if author == "" {
+			author = e.Author
+		}
+		if author == "" {
+			author = feed.Channel.Title
+		}
+		body := e.Content
+		if body == "" {
+			body = e.Description
+		}
+		id := e.Guid
+		if id == "" {
+			id = e.Link
+		}
+
+		ret = append(ret, Item{
+			Id:        id,
+			Author:    author,
+			Title:     e.Title,
+			Body:      htmlToText(body),
+			CreatedAt: parseFeedTime(e.PubDate),
+			Flair:     e.Category,
+		})
+	}
+
+	return ret, nil
+}
+
+func parseAtom(data []byte) ([]Item, error) {
+	feed := atomFeed{}
+	if err := xml.Unmarshal(data, &feed); err != nil {
+		return nil, err
+	}
+
+	ret := make([]Item, 0, len(feed.Entries))
+	for _, e := range feed.Entries {
+		author := feed.Author.Name
+		if len(e.Authors) > 0 && e.Authors[0].Name != "" {
+			author = e.Authors[0].Name
+		}
+		if author == "" {
+			author = feed.Title
+		}
+		body := e.Content
+		if body == "" {
+			body = e.Summary
+		}
+		date := e.Published
+		if date == "" {
+			date = e.Updated
+		}
+
+		ret = append(ret, Item{
+			Id:        e.Id,
+			Author:    author,
+			Title:     e.Title,
+			Body:      htmlToText(body),
+			CreatedAt: parseFeedTime(date),
+			Flair:     e.Category.Term,
+		})
+	}
+
+	return ret, nil
+}
+
+// Parses the dates used by RSS (RFC 1123 and friends) and Atom (RFC 3339)
+// Returns the zero time if the date cannot be parsed
+func parseFeedTime(s string) time.Time {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.UTC()
+	}
+	if t, err := mail.ParseDate(s); err == nil {
+		return t.UTC()
+	}
+	return time.Time{}
+}
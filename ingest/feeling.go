@@ -0,0 +1,134 @@
+package ingest
+
+import (
+	"strings"
+	"unicode"
+
+	"cornchip.com/libwara/v2"
+)
+
+// Picks the Feeling a Mii shows for an Item
+// Classifiers return FEELING_DEFAULT when they have no opinion
+type FeelingClassifier interface {
+	Classify(item *Item) libwara.Feeling
+}
+
+// Adapts a function to a FeelingClassifier
+type FeelingFunc func(item *Item) libwara.Feeling
+
+func (f FeelingFunc) Classify(item *Item) libwara.Feeling {
+	return f(item)
+}
+
+// Asks each classifier in order, and returns the first Feeling that is not FEELING_DEFAULT
+type ClassifierChain []FeelingClassifier
+
+func (c ClassifierChain) Classify(item *Item) libwara.Feeling {
+	for _, classifier := range c {
+		if f := classifier.Classify(item); f != libwara.FEELING_DEFAULT {
+			return f
+		}
+	}
+	return libwara.FEELING_DEFAULT
+}
+
+// Classifies by counting lexicon words in the title and body of an Item
+type LexiconClassifier struct {
+	lookup    map[string]libwara.Feeling
+	MinHits   int             // Number of matching words needed before a Feeling is picked
+	Exclaimed libwara.Feeling // Feeling for titles with "!!" and no matching words
+}
+
+// Creates a LexiconClassifier from a word list per Feeling
+// A nil lexicon uses the built in English lexicon
+func NewLexiconClassifier(lexicon map[libwara.Feeling][]string) *LexiconClassifier {
+	if lexicon == nil {
+		lexicon = defaultLexicon
+	}
+
+	c := &LexiconClassifier{
+		lookup:    map[string]libwara.Feeling{},
+		MinHits:   1,
+		Exclaimed: libwara.FEELING_EXCITED,
+	}
+	for feeling, words := range lexicon {
+		for _, w := range words {
+			c.lookup[strings.ToLower(w)] = feeling
+		}
+	}
+
+	return c
+}
+
+func (c *LexiconClassifier) Classify(item *Item) libwara.Feeling {
+	text := item.Title + " " + item.Body
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	hits := map[libwara.Feeling]int{}
+	for _, w := range words {
+		if f, ok := c.lookup[w]; ok {
+			hits[f]++
+		}
+	}
+
+	// Ties go to the lowest Feeling so the result does not depend on map order
+	best, bestHits := libwara.FEELING_DEFAULT, 0
+	for f := libwara.FEELING_DANCE; f <= libwara.FEELING_SAD; f++ {
+		if hits[f] > bestHits {
+			best, bestHits = f, hits[f]
+		}
+	}
+	if bestHits >= c.MinHits && bestHits > 0 {
+		return best
+	}
+
+	if strings.Contains(item.Title, "!!") {
+		return c.Exclaimed
+	}
+
+	return libwara.FEELING_DEFAULT
+}
+
+// Maps flair text to a Feeling, ignoring case
+type FlairRule map[string]libwara.Feeling
+
+func (r FlairRule) Classify(item *Item) libwara.Feeling {
+	for flair, f := range r {
+		if strings.EqualFold(flair, item.Flair) {
+			return f
+		}
+	}
+	return libwara.FEELING_DEFAULT
+}
+
+// Maps the upvote ratio of an Item to a Feeling
+// Items at or below Low get LowFeeling, items at or above High get HighFeeling
+type UpvoteRatioRule struct {
+	Low         float64
+	LowFeeling  libwara.Feeling
+	High        float64
+	HighFeeling libwara.Feeling
+}
+
+func (r UpvoteRatioRule) Classify(item *Item) libwara.Feeling {
+	if item.UpvoteRatio == 0 {
+		return libwara.FEELING_DEFAULT
+	}
+	if item.UpvoteRatio <= r.Low {
+		return r.LowFeeling
+	}
+	if item.UpvoteRatio >= r.High {
+		return r.HighFeeling
+	}
+	return libwara.FEELING_DEFAULT
+}
+
+// Controversial posts look angry, very well received posts dance, everything else goes by the lexicon
+func DefaultFeelingClassifier() FeelingClassifier {
+	return ClassifierChain{
+		UpvoteRatioRule{Low: 0.5, LowFeeling: libwara.FEELING_ANGRY, High: 0.99, HighFeeling: libwara.FEELING_DANCE},
+		NewLexiconClassifier(nil),
+	}
+}
@@ -0,0 +1,28 @@
+package ingest
+
+import "cornchip.com/libwara/v2"
+
+// Words that hint at a Feeling, matched against lower case words of an Item
+var defaultLexicon = map[libwara.Feeling][]string{
+	libwara.FEELING_DANCE: {
+		"dance", "dancing", "party", "celebrate", "celebration", "finally", "victory", "won", "win", "beat",
+		"cleared", "completed", "hype", "lets", "woohoo", "yay", "music", "song",
+	},
+	libwara.FEELING_EXCITED: {
+		"amazing", "awesome", "excited", "love", "loving", "great", "best", "beautiful", "cool", "fun",
+		"favorite", "favourite", "happy", "incredible", "nice", "wow", "new", "announced", "announcement",
+		"reveal", "revealed", "thanks", "thank", "glad", "perfect",
+	},
+	libwara.FEELING_SHOCKED: {
+		"shocked", "surprised", "surprising", "unexpected", "omg", "wtf", "what", "whoa", "insane",
+		"crazy", "unbelievable", "wait", "leak", "leaked", "discovered", "secret", "weird", "strange", "huh",
+	},
+	libwara.FEELING_ANGRY: {
+		"angry", "hate", "hated", "worst", "terrible", "awful", "garbage", "trash", "broken", "unfair",
+		"annoying", "annoyed", "stupid", "ridiculous", "scam", "rage", "mad", "furious", "ban", "banned",
+	},
+	libwara.FEELING_SAD: {
+		"sad", "miss", "missing", "rip", "died", "dead", "death", "lost", "lose", "gone", "goodbye",
+		"shutdown", "shut", "closing", "cry", "crying", "lonely", "sorry", "unfortunately", "depressed",
+	},
+}
@@ -0,0 +1,226 @@
+package ingest
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"cornchip.com/libwara/v2"
+)
+
+// Turns the raw text of an Item into something the console can show in a Post body
+// Each step can be turned off on its own
+type BodyFormatter struct {
+	DecodeEntities bool   // Decode HTML entities such as &amp;
+	StripMarkdown  bool   // Remove Markdown syntax, keeping the text
+	URLReplacement string // Text bare URLs are replaced with, URLs are kept if blank
+	ReplaceGlyphs  bool   // Replace glyphs the console font cannot show
+	GlyphFallback  string // Text for unsupported glyphs with no specific fallback
+	LineWidth      int    // Characters per line, lines are not wrapped if 0
+	MaxLength      int    // Maximum characters in the body, the body is not truncated if 0
+	Ellipsis       string // Appended to truncated bodies
+	CaptionLength  int    // Maximum characters in the caption of a painting
+}
+
+// Formatting that fits the plaza speech bubble
+var DefaultFormatter = BodyFormatter{
+	DecodeEntities: true,
+	StripMarkdown:  true,
+	URLReplacement: "[link]",
+	ReplaceGlyphs:  true,
+	GlyphFallback:  "",
+	LineWidth:      30,
+	MaxLength:      int(libwara.MAX_BODY_LENGTH),
+	Ellipsis:       "...",
+	CaptionLength:  60,
+}
+
+var (
+	mdCodeFence   = regexp.MustCompile("(?m)^[ \\t]*```.*$\n?")
+	mdImage       = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	mdLink        = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	mdHeading     = regexp.MustCompile(`(?m)^[ \t]{0,3}#{1,6}[ \t]+`)
+	mdQuote       = regexp.MustCompile(`(?m)^[ \t]{0,3}(>[ \t]?)+`)
+	mdListItem    = regexp.MustCompile(`(?m)^([ \t]*)[*+][ \t]+`)
+	mdRule        = regexp.MustCompile(`(?m)^[ \t]{0,3}([-*_][ \t]*){3,}$`)
+	mdSpoiler     = regexp.MustCompile(`>!(.*?)!<`)
+	mdBold        = regexp.MustCompile(`(\*\*|__)(.+?)(\*\*|__)`)
+	mdItalic      = regexp.MustCompile(`(^|[^\w*])[*_]([^*_\s][^*_]*?)[*_]($|[^\w*])`)
+	mdStrike      = regexp.MustCompile(`~~(.+?)~~`)
+	mdCode        = regexp.MustCompile("`([^`]*)`")
+	mdSuperscript = regexp.MustCompile(`\^\(([^)]*)\)|\^(\S+)`)
+	mdEscape      = regexp.MustCompile(`\\([\\*_~^#>\[\]()` + "`" + `.!|+-])`)
+	bareURL       = regexp.MustCompile(`https?://[^\s)\]]+`)
+	blankLines    = regexp.MustCompile(`\n{3,}`)
+	htmlBreak     = regexp.MustCompile(`(?i)<br\s*/?>|</p>|</li>|</h[1-6]>`)
+	htmlTag       = regexp.MustCompile(`<[^>]*>`)
+	spaceRuns     = regexp.MustCompile(`[ \t]+`)
+)
+
+// Runs every enabled step over text
+func (f *BodyFormatter) Format(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	if f.DecodeEntities {
+		text = html.UnescapeString(text)
+	}
+	if f.StripMarkdown {
+		text = stripMarkdown(text)
+	}
+	if f.URLReplacement != "" {
+		text = bareURL.ReplaceAllString(text, f.URLReplacement)
+	}
+	if f.ReplaceGlyphs {
+		text = replaceGlyphs(text, f.GlyphFallback)
+	}
+	text = collapseWhitespace(text)
+	if f.LineWidth > 0 {
+		text = wrapLines(text, f.LineWidth)
+	}
+	if f.MaxLength > 0 {
+		text = truncate(text, f.MaxLength, f.Ellipsis)
+	}
+
+	return text
+}
+
+// Formats a short caption, used as the body of Posts with a painting
+func (f *BodyFormatter) Caption(text string) string {
+	caption := *f
+	if caption.CaptionLength > 0 {
+		caption.MaxLength = caption.CaptionLength
+	}
+	return caption.Format(text)
+}
+
+// Removes Markdown syntax, keeping the text it marks up
+// Escaped characters are hidden in the private use area while the syntax is removed, then restored
+func stripMarkdown(text string) string {
+	text = mdEscape.ReplaceAllStringFunc(text, func(m string) string {
+		return string(rune(0xE000) + rune(m[1]))
+	})
+	text = mdCodeFence.ReplaceAllString(text, "")
+	text = mdImage.ReplaceAllString(text, "$1")
+	text = mdLink.ReplaceAllString(text, "$1")
+	text = mdRule.ReplaceAllString(text, "")
+	text = mdHeading.ReplaceAllString(text, "")
+	text = mdSpoiler.ReplaceAllString(text, "$1")
+	text = mdQuote.ReplaceAllString(text, "")
+	text = mdListItem.ReplaceAllString(text, "$1- ")
+	text = mdBold.ReplaceAllString(text, "$2")
+	text = mdStrike.ReplaceAllString(text, "$1")
+	text = mdItalic.ReplaceAllString(text, "$1$2$3")
+	text = mdCode.ReplaceAllString(text, "$1")
+	text = mdSuperscript.ReplaceAllString(text, "$1$2")
+	return strings.Map(func(r rune) rune {
+		if r >= 0xE000 && r < 0xE080 {
+			return r - 0xE000
+		}
+		return r
+	}, text)
+}
+
+// Turns HTML from feeds and outboxes into plain text, keeping line and paragraph breaks
+// Entities are left for the formatter to decode
+func htmlToText(s string) string {
+	s = htmlBreak.ReplaceAllString(s, "$0\n")
+	return strings.TrimSpace(htmlTag.ReplaceAllString(s, ""))
+}
+
+// Checks if the console font can show a rune
+// The font covers the Basic Multilingual Plane apart from symbol and emoji blocks
+func supportedGlyph(r rune) bool {
+	switch {
+	case r == '\n' || r == '\t':
+		return true
+	case unicode.IsControl(r):
+		return false
+	case r > 0xFFFF:
+		return false
+	case r >= 0x2190 && r <= 0x2BFF: // arrows, technical, dingbats, misc symbols
+		return false
+	case r >= 0xFE00 && r <= 0xFE0F: // variation selectors
+		return false
+	case r == 0x200D: // zero width joiner
+		return false
+	}
+	return true
+}
+
+// Replaces glyphs the console font cannot show
+func replaceGlyphs(text, fallback string) string {
+	var out strings.Builder
+	for _, r := range text {
+		if s, ok := glyphFallbacks[r]; ok {
+			out.WriteString(s)
+			continue
+		}
+		if !supportedGlyph(r) {
+			out.WriteString(fallback)
+			continue
+		}
+		out.WriteRune(r)
+	}
+	return out.String()
+}
+
+// Trims lines, collapses runs of spaces and removes extra blank lines
+func collapseWhitespace(text string) string {
+	lines := strings.Split(text, "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(spaceRuns.ReplaceAllString(lines[i], " "))
+	}
+	text = strings.Join(lines, "\n")
+	text = blankLines.ReplaceAllString(text, "\n\n")
+	return strings.TrimSpace(text)
+}
+
+// Wraps every line to width characters, breaking words that do not fit on a line of their own
+func wrapLines(text string, width int) string {
+	var out strings.Builder
+	for i, line := range strings.Split(text, "\n") {
+		if i > 0 {
+			out.WriteByte('\n')
+		}
+
+		col := 0
+		for _, word := range strings.Fields(line) {
+			runes := []rune(word)
+			if col > 0 && col+1+len(runes) > width {
+				out.WriteByte('\n')
+				col = 0
+			} else if col > 0 {
+				out.WriteByte(' ')
+				col++
+			}
+			for len(runes) > width-col {
+				out.WriteString(string(runes[:width-col]))
+				out.WriteByte('\n')
+				runes = runes[width-col:]
+				col = 0
+			}
+			out.WriteString(string(runes))
+			col += len(runes)
+		}
+	}
+	return out.String()
+}
+
+// Cuts text down to max characters including the ellipsis, preferring to cut between words
+func truncate(text string, max int, ellipsis string) string {
+	if utf8.RuneCountInString(text) <= max {
+		return text
+	}
+
+	keep := max - utf8.RuneCountInString(ellipsis)
+	if keep <= 0 {
+		return string([]rune(ellipsis)[:max])
+	}
+
+	runes := []rune(text)[:keep]
+	if cut := strings.LastIndexAny(string(runes), " \n"); cut > len(string(runes))/2 {
+		return strings.TrimRight(string(runes)[:cut], " \n") + ellipsis
+	}
+	return strings.TrimRight(string(runes), " \n") + ellipsis
+}
@@ -0,0 +1,212 @@
+package ingest
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestStripMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", "just text", "just text"},
+		{"bold", "**bold** and __bold__", "bold and bold"},
+		{"italic", "*italic* and _italic_", "italic and italic"},
+		{"snake case", "snake_case_name", "snake_case_name"},
+		{"strike", "~~gone~~", "gone"},
+		{"code", "run `go test` now", "run go test now"},
+		{"code fence", "```go\nx := 1\n```", "x := 1\n"},
+		{"heading", "## Heading\ntext", "Heading\ntext"},
+		{"hashtag", "#hashtag", "#hashtag"},
+		{"quote", "> quoted\n>> nested", "quoted\nnested"},
+		{"list", "* one\n+ two\n- three", "- one\n- two\n- three"},
+		{"rule", "above\n***\nbelow", "above\n\nbelow"},
+		{"link", "[text](https://example.com)", "text"},
+		{"image", "![alt](https://example.com/a.png)", "alt"},
+		{"spoiler", ">!secret!<", "secret"},
+		{"superscript", "x^2 and ^(two words)", "x2 and two words"},
+		{"escapes", `\*not italic\* and 1\. and \[x\]`, "*not italic* and 1. and [x]"},
+		{"non ascii", "**héllo** _wörld_ ~~日本~~", "héllo wörld 日本"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stripMarkdown(tt.in); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatEntitiesAndLinks(t *testing.T) {
+	f := BodyFormatter{DecodeEntities: true, StripMarkdown: true, URLReplacement: "[link]"}
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"named entities", "Tom &amp; Jerry &lt;3 &gt;", "Tom & Jerry <3 >"},
+		{"numeric entities", "&#39;quoted&#39; &#x263A;", "'quoted' ☺"},
+		{"double encoded", "&amp;amp;", "&amp;"},
+		{"unknown entity", "&bogus;", "&bogus;"},
+		{"bare url", "see https://example.com/a?b=c for more", "see [link] for more"},
+		{"http url", "http://example.com", "[link]"},
+		{"url in parens", "(https://example.com)", "([link])"},
+		{"markdown link keeps text", "[the docs](https://example.com) and https://x.org", "the docs and [link]"},
+		{"entity in link", "[a &amp; b](https://example.com)", "a & b"},
+		{"windows newlines", "a\r\nb", "a\nb"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := f.Format(tt.in); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	keep := BodyFormatter{}
+	if got := keep.Format("https://example.com &amp; **x**"); got != "https://example.com &amp; **x**" {
+		t.Errorf("disabled steps changed the text: %q", got)
+	}
+}
+
+func TestReplaceGlyphs(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       string
+		fallback string
+		want     string
+	}{
+		{"quotes", "‘a’ “b”", "", "'a' \"b\""},
+		{"dashes", "a–b—c", "", "a-b-c"},
+		{"emoji with fallback", "hi 😀", "", "hi :D"},
+		{"emoji without fallback", "hi 🦀!", "?", "hi ?!"},
+		{"variation selector", "❤️", "", "<3"},
+		{"joined emoji", "👨‍👩", "", ""},
+		{"kana kept", "こんにちは", "", "こんにちは"},
+		{"accents kept", "café naïve", "", "café naïve"},
+		{"control removed", "a\x07b", "", "ab"},
+		{"newlines kept", "a\nb\tc", "", "a\nb\tc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := replaceGlyphs(tt.in, tt.fallback); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrapLines(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		width int
+		want  string
+	}{
+		{"fits", "short line", 20, "short line"},
+		{"wraps between words", "the quick brown fox", 10, "the quick\nbrown fox"},
+		{"exact width", "abcde fghij", 5, "abcde\nfghij"},
+		{"long word", "abcdefghij", 4, "abcd\nefgh\nij"},
+		{"long word after text", "ab cdefghij", 4, "ab\ncdef\nghij"},
+		{"keeps paragraphs", "one two\n\nthree", 5, "one\ntwo\n\nthree"},
+		{"counts runes", "日本語 日本語", 3, "日本語\n日本語"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := wrapLines(tt.in, tt.width)
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			for _, line := range strings.Split(got, "\n") {
+				if utf8.RuneCountInString(line) > tt.width {
+					t.Errorf("line %q is longer than %d", line, tt.width)
+				}
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       string
+		max      int
+		ellipsis string
+		want     string
+	}{
+		{"fits", "short", 10, "...", "short"},
+		{"exact", "0123456789", 10, "...", "0123456789"},
+		{"between words", "hello wonderful world", 20, "...", "hello wonderful..."},
+		{"word early in text", "hello wonderful world", 15, "...", "hello wonder..."},
+		{"inside word", "abcdefghijklmnop", 10, "...", "abcdefg..."},
+		{"multibyte", "日本語のテキストです", 6, "…", "日本語のテ…"},
+		{"emoji", "😀😀😀😀😀😀", 4, "...", "😀..."},
+		{"ellipsis too long", "abcdefgh", 2, "...", ".."},
+		{"no ellipsis", "abcdefgh", 4, "", "abcd"},
+		{"trailing newline", "abc\n\ndefghijkl", 7, "...", "abc..."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncate(tt.in, tt.max, tt.ellipsis)
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("%q is not valid UTF-8", got)
+			}
+			if n := utf8.RuneCountInString(got); n > tt.max {
+				t.Errorf("%q has %d characters, more than %d", got, n, tt.max)
+			}
+		})
+	}
+}
+
+func TestDefaultFormatter(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			"reddit post",
+			"**Patch notes** &amp; more:\n\n* Fixed [the bug](https://example.com)\n* See https://reddit.com/r/x 😀",
+			"Patch notes & more:\n\n- Fixed the bug\n- See [link] :D",
+		},
+		{
+			"wrapped",
+			"This sentence is a little too long for a single speech bubble line",
+			"This sentence is a little too\nlong for a single speech\nbubble line",
+		},
+		{
+			"whitespace",
+			"  lots   of\tspace  \n\n\n\nand blank lines  ",
+			"lots of space\n\nand blank lines",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DefaultFormatter.Format(tt.in); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	long := strings.Repeat("word ", 200)
+	got := DefaultFormatter.Format(long)
+	if n := utf8.RuneCountInString(got); n > DefaultFormatter.MaxLength {
+		t.Errorf("body has %d characters, more than %d", n, DefaultFormatter.MaxLength)
+	}
+	if !strings.HasSuffix(got, DefaultFormatter.Ellipsis) {
+		t.Errorf("truncated body does not end with the ellipsis: %q", got)
+	}
+}
+
+func TestCaption(t *testing.T) {
+	caption := DefaultFormatter.Caption(strings.Repeat("a", 100))
+	if n := utf8.RuneCountInString(caption); n > DefaultFormatter.CaptionLength {
+		t.Errorf("caption has %d characters, more than %d", n, DefaultFormatter.CaptionLength)
+	}
+}
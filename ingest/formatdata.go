@@ -0,0 +1,15 @@
+package ingest
+
+// Fallbacks for glyphs the console font cannot show
+var glyphFallbacks = map[rune]string{
+	'‘': "'", '’': "'", '“': "\"", '”': "\"",
+	'–': "-", '—': "-", '…': "...", ' ': " ",
+	'•': "-", '™': "(TM)",
+	'\U0001F600': ":D", '\U0001F603': ":D", '\U0001F604': ":D", '\U0001F601': ":D",
+	'\U0001F602': "XD", '\U0001F923': "XD", '\U0001F642': ":)", '\U0001F60A': ":)",
+	'\U0001F609': ";)", '\U0001F61B': ":P", '\U0001F61C': ";P", '\U0001F641': ":(",
+	'☹': ":(", '\U0001F622': ":'(", '\U0001F62D': ":'(", '\U0001F620': ">:(",
+	'\U0001F621': ">:(", '\U0001F62E': ":O", '\U0001F632': ":O", '\U0001F610': ":|",
+	'❤': "<3", '\U0001F495': "<3", '\U0001F494': "</3", '\U0001F44D': "(y)",
+	'\U0001F44E': "(n)",
+}
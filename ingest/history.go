@@ -0,0 +1,178 @@
+package ingest
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	postsBucket = []byte("posts") // Item ID to postRecord
+	miisBucket  = []byte("miis")  // Author to encoded Mii
+)
+
+// Remembers which Items went into which 1stNUP and the Mii of every author, across runs
+// Backed by a bbolt file, so only one process can have it open at a time
+type History struct {
+	db *bolt.DB
+}
+
+// Every time an Item was used
+type postRecord struct {
+	Uses []postUse `json:"uses"`
+}
+
+// One 1stNUP an Item went into
+type postUse struct {
+	Nup string    `json:"nup"`
+	At  time.Time `json:"at"`
+}
+
+// Opens the History in a file, creating it if needed
+func OpenHistory(path string) (*History, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{postsBucket, miisBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &History{db: db}, nil
+}
+
+// Closes the History file
+func (h *History) Close() error {
+	return h.db.Close()
+}
+
+// Records that Items went into a 1stNUP at a time
+func (h *History) Record(nup string, at time.Time, items []*Item) error {
+	return h.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(postsBucket)
+		for _, item := range items {
+			record := postRecord{}
+			if data := b.Get([]byte(item.Id)); data != nil {
+				if err := json.Unmarshal(data, &record); err != nil {
+					return err
+				}
+			}
+			record.Uses = append(record.Uses, postUse{Nup: nup, At: at.UTC()})
+
+			data, err := json.Marshal(record)
+			if err != nil {
+				return err
+			}
+			if err = b.Put([]byte(item.Id), data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Returns when an Item was last used, and false if it never was
+func (h *History) LastUsed(id string) (time.Time, bool, error) {
+	record := postRecord{}
+	err := h.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(postsBucket).Get([]byte(id))
+		if data == nil {
+			return nil
+		}
+		return json.Unmarshal(data, &record)
+	})
+	if err != nil || len(record.Uses) == 0 {
+		return time.Time{}, false, err
+	}
+	return record.Uses[len(record.Uses)-1].At, true, nil
+}
+
+// Returns the 1stNUPs an Item went into, oldest first
+func (h *History) Nups(id string) ([]string, error) {
+	record := postRecord{}
+	err := h.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(postsBucket).Get([]byte(id))
+		if data == nil {
+			return nil
+		}
+		return json.Unmarshal(data, &record)
+	})
+
+	ret := make([]string, len(record.Uses))
+	for i := range record.Uses {
+		ret[i] = record.Uses[i].Nup
+	}
+	return ret, err
+}
+
+// Puts Items that were never used first, in their original order, followed by used Items, least recently used first
+// Rebuilding every day then rotates through the Items instead of picking the same ones again
+// Comments stay right after the Item they reply to and move with it
+func (h *History) Prefer(items []Item) ([]Item, error) {
+	groups := [][]int{}
+	groupOf := map[string]int{}
+	for i := range items {
+		g, ok := groupOf[items[i].ParentId]
+		if !ok || items[i].ParentId == "" {
+			g = len(groups)
+			groups = append(groups, nil)
+		}
+		groups[g] = append(groups[g], i)
+		groupOf[items[i].Id] = g
+	}
+
+	lastUsed := make([]time.Time, len(groups))
+	for g := range groups {
+		at, _, err := h.LastUsed(items[groups[g][0]].Id)
+		if err != nil {
+			return nil, err
+		}
+		lastUsed[g] = at
+	}
+
+	order := make([]int, len(groups))
+	for i := range order {
+		order[i] = i
+	}
+	// Unused Items have the zero time, so they sort first
+	sort.SliceStable(order, func(a, b int) bool {
+		return lastUsed[order[a]].Before(lastUsed[order[b]])
+	})
+
+	ret := make([]Item, 0, len(items))
+	for _, g := range order {
+		for _, i := range groups[g] {
+			ret = append(ret, items[i])
+		}
+	}
+	return ret, nil
+}
+
+// Returns the Mii stored for an author, or "" if there is none
+func (h *History) Mii(author string) (string, error) {
+	mii := ""
+	err := h.db.View(func(tx *bolt.Tx) error {
+		mii = string(tx.Bucket(miisBucket).Get([]byte(author)))
+		return nil
+	})
+	return mii, err
+}
+
+// Stores the Mii of an author
+func (h *History) SetMii(author string, mii string) error {
+	return h.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(miisBucket).Put([]byte(author), []byte(mii))
+	})
+}
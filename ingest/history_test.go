@@ -0,0 +1,50 @@
+package ingest
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPreferKeepsComments(t *testing.T) {
+	history, err := OpenHistory(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer history.Close()
+
+	items := []Item{
+		{Id: "t3_a"},
+		{Id: "t1_a1", ParentId: "t3_a"},
+		{Id: "t1_a2", ParentId: "t3_a"},
+		{Id: "t3_b"},
+		{Id: "t1_b1", ParentId: "t3_b"},
+		{Id: "t3_c"},
+		{Id: "t1_x", ParentId: "t3_gone"},
+	}
+	at := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := history.Record("old.xml", at, []*Item{&items[0]}); err != nil {
+		t.Fatal(err)
+	}
+	if err := history.Record("new.xml", at.Add(time.Hour), []*Item{&items[3], &items[1]}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := history.Prefer(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"t3_c", "t1_x", "t3_a", "t1_a1", "t1_a2", "t3_b", "t1_b1"}
+	if len(got) != len(want) {
+		t.Fatalf("got %d Items, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].Id != want[i] {
+			ids := []string{}
+			for _, item := range got {
+				ids = append(ids, item.Id)
+			}
+			t.Fatalf("got %v, want %v", ids, want)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"cornchip.com/libwara/v2"
+)
+
+// Downloads the icon of a subreddit and encodes it as a Topic icon
+// The community icon is tried first, then the old style icon, then the banners
+func (c *Client) SubredditIcon(ctx context.Context, subreddit string) (string, error) {
+	about, err := c.About(ctx, subreddit)
+	if err != nil {
+		return "", err
+	}
+
+	urls := about.IconURLs()
+	if len(urls) == 0 {
+		return "", errors.New("subreddit " + subreddit + " has no icon")
+	}
+
+	for _, u := range urls {
+		icon, iconErr := c.encodeIcon(ctx, u)
+		if iconErr == nil {
+			return icon, nil
+		}
+		err = iconErr
+	}
+
+	return "", err
+}
+
+// Downloads an image and encodes it as a Topic icon
+func (c *Client) encodeIcon(ctx context.Context, rawURL string) (string, error) {
+	path, temporary, err := c.download(ctx, rawURL)
+	if err != nil {
+		return "", err
+	}
+	if temporary {
+		defer os.Remove(path)
+	}
+
+	return libwara.CreateImage(path, libwara.IconSize)
+}
+
+// Returns the icon for a Topic, falling back to b.IconFallback when the subreddit icon cannot be used
+// The fallback is given the subreddit name, or the Topic name for Topics without a subreddit
+// A blank icon keeps the default icon of the Topic
+func (b *Builder) topicIcon(ctx context.Context, topic *TopicConfig, name string) string {
+	if b.Client != nil && topic.Subreddit != "" {
+		icon, err := b.Client.SubredditIcon(ctx, topic.Subreddit)
+		if err == nil {
+			return icon
+		}
+	}
+
+	if b.IconFallback == nil {
+		return ""
+	}
+	if topic.Subreddit != "" {
+		name = topic.Subreddit
+	}
+	icon, err := b.IconFallback(name)
+	if err != nil {
+		return ""
+	}
+	return icon
+}
@@ -0,0 +1,178 @@
+package ingest
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cornchip.com/libwara/v2"
+)
+
+// Returns a fixture server with a subreddit whose community icon is broken and whose old style icon works
+func iconServer(t *testing.T) http.Handler {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, 16, 16))
+	for i := range img.Pix {
+		img.Pix[i] = 0xFF
+	}
+	img.SetNRGBA(3, 4, color.NRGBA{R: 0x12, G: 0x34, B: 0x56, A: 0xFF})
+	icon := bytes.Buffer{}
+	if err := png.Encode(&icon, img); err != nil {
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/r/wiiu/about.json", func(w http.ResponseWriter, r *http.Request) {
+		base := "http://" + r.Host
+		w.Write([]byte(`{"kind":"t5","data":{"display_name":"wiiu",` +
+			`"community_icon":"` + base + `/broken.png?width=256&amp;s=x",` +
+			`"icon_img":"` + base + `/icon.png"}}`))
+	})
+	mux.HandleFunc("/r/private/about.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"kind":"Listing","data":{}}`))
+	})
+	mux.HandleFunc("/broken.png", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("s") != "x" || r.URL.Query().Get("width") != "256" {
+			t.Errorf("icon URL was not unescaped: %q", r.URL.RawQuery)
+		}
+		w.Write([]byte("not a png"))
+	})
+	mux.HandleFunc("/icon.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(icon.Bytes())
+	})
+	return mux
+}
+
+// Checks an encoded icon decodes to an icon sized image
+func checkIcon(t *testing.T, encoded string) {
+	t.Helper()
+	img, err := libwara.DecodeImage(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 128 || b.Dy() != 128 {
+		t.Errorf("icon is %dx%d", b.Dx(), b.Dy())
+	}
+}
+
+// Returns the files left in the temporary directory
+func tempFiles(t *testing.T) []string {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(os.TempDir(), "reddittowara-*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestSubredditIcon(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	c, _ := testClient(t, iconServer(t))
+
+	icon, err := c.SubredditIcon(context.Background(), "wiiu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkIcon(t, icon)
+	if files := tempFiles(t); len(files) != 0 {
+		t.Errorf("temporary files were left behind: %v", files)
+	}
+
+	if _, err := c.SubredditIcon(context.Background(), "private"); err == nil {
+		t.Error("no error for a subreddit without an about page")
+	}
+	if _, err := c.SubredditIcon(context.Background(), "missing"); err == nil {
+		t.Error("no error for a missing subreddit")
+	}
+}
+
+func TestSubredditIconCached(t *testing.T) {
+	c, _ := testClient(t, iconServer(t))
+	c.CacheDir = t.TempDir()
+
+	icon, err := c.SubredditIcon(context.Background(), "wiiu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkIcon(t, icon)
+}
+
+func TestDownload(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	c, _ := testClient(t, iconServer(t))
+	rawURL := c.BaseURL + "/icon.png"
+
+	path, temporary, err := c.download(context.Background(), rawURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !temporary || !strings.HasPrefix(filepath.Base(path), "reddittowara-") {
+		t.Errorf("got %q, temporary %v, want a temporary file without a cache", path, temporary)
+	}
+	os.Remove(path)
+
+	c.CacheDir = t.TempDir()
+	path, temporary, err = c.download(context.Background(), rawURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if temporary || path != c.cachePath(rawURL) {
+		t.Errorf("got %q, temporary %v, want the cache file", path, temporary)
+	}
+
+	// A cache directory that cannot be created falls back to a temporary file
+	blocker := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(blocker, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c.CacheDir = filepath.Join(blocker, "cache")
+	path, temporary, err = c.download(context.Background(), rawURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !temporary {
+		t.Errorf("got %q, want a temporary file when caching fails", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(path)
+	if _, err := png.Decode(bytes.NewReader(data)); err != nil {
+		t.Errorf("downloaded file is not the icon: %v", err)
+	}
+
+	if _, _, err := c.download(context.Background(), c.BaseURL+"/missing.png"); err == nil {
+		t.Error("no error for a missing file")
+	}
+}
+
+func TestDownloadUnwritableCache(t *testing.T) {
+	c, _ := testClient(t, iconServer(t))
+	c.CacheDir = t.TempDir()
+	rawURL := c.BaseURL + "/icon.png"
+
+	// A directory in place of the cache file makes storing fail
+	path := c.cachePath(rawURL)
+	if err := os.Mkdir(path, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	got, temporary, err := c.download(context.Background(), rawURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if temporary {
+		defer os.Remove(got)
+	}
+	if got == path || !temporary {
+		t.Errorf("got %q, temporary %v, want a temporary file when the cache file cannot be written", got, temporary)
+	}
+}
@@ -0,0 +1,21 @@
+package ingest
+
+import "time"
+
+// A single piece of content that can become a Post
+type Item struct {
+	Id        string    // Source specific ID, the Reddit fullname for Reddit
+	ParentId  string    // ID of the Item this is a reply to, blank for top level items
+	Author    string    // Username of the author
+	Title     string    // Title of the submission
+	Body      string    // Text of the submission, may be blank
+	Score     int       // Net upvotes
+	Comments  int       // Number of comments
+	CreatedAt time.Time // Time the item was posted
+
+	Flair       string  // Flair text, may be blank
+	UpvoteRatio float64 // Fraction of votes that were upvotes, 0 if unknown
+	NSFW        bool    // Marked as not safe for work
+	Spoiler     bool    // Marked as a spoiler
+	ImageURL    string  // Image shown by the item, blank for text items
+}
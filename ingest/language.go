@@ -0,0 +1,135 @@
+package ingest
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"cornchip.com/libwara/v2"
+)
+
+// Number of trigrams kept in each language profile
+const profileSize = 300
+
+// Guesses the language of a text without any network access
+// Scripts that belong to one language are recognised directly, Latin script languages are told apart by
+// comparing trigram frequencies against a profile of each language
+type LanguageDetector struct {
+	Fallback libwara.LanguageId // Language used when the text is too short to tell
+	MinRunes int                // Texts with fewer letters than this get the Fallback
+
+	profiles map[libwara.LanguageId]map[string]int
+}
+
+// Creates a LanguageDetector with profiles built from the built in samples
+func NewLanguageDetector(fallback libwara.LanguageId) *LanguageDetector {
+	d := &LanguageDetector{
+		Fallback: fallback,
+		MinRunes: 12,
+		profiles: map[libwara.LanguageId]map[string]int{},
+	}
+	for lang, sample := range languageSamples {
+		d.profiles[lang] = trigramProfile(sample)
+	}
+	return d
+}
+
+// Returns the trigrams of a text, ranked from most to least common
+func trigramProfile(text string) map[string]int {
+	counts := map[string]int{}
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	}) {
+		runes := []rune(" " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			counts[string(runes[i:i+3])]++
+		}
+	}
+
+	grams := make([]string, 0, len(counts))
+	for g := range counts {
+		grams = append(grams, g)
+	}
+	sort.Slice(grams, func(i, j int) bool {
+		if counts[grams[i]] != counts[grams[j]] {
+			return counts[grams[i]] > counts[grams[j]]
+		}
+		return grams[i] < grams[j]
+	})
+	if len(grams) > profileSize {
+		grams = grams[:profileSize]
+	}
+
+	ranks := make(map[string]int, len(grams))
+	for i, g := range grams {
+		ranks[g] = i
+	}
+	return ranks
+}
+
+// Guesses the language of a text
+func (d *LanguageDetector) Detect(text string) libwara.LanguageId {
+	letters := 0
+	scripts := map[string]int{}
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		switch {
+		case unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r):
+			scripts["kana"]++
+		case unicode.Is(unicode.Hangul, r):
+			scripts["hangul"]++
+		case unicode.Is(unicode.Han, r):
+			scripts["han"]++
+		case unicode.Is(unicode.Cyrillic, r):
+			scripts["cyrillic"]++
+		}
+	}
+	if letters < d.MinRunes {
+		return d.Fallback
+	}
+
+	// Japanese mixes kana with kanji, so any kana at all means Japanese
+	switch {
+	case scripts["kana"] > 0:
+		return libwara.LanguageJapanese
+	case scripts["hangul"]*2 > letters:
+		return libwara.LanguageKorean
+	case scripts["han"]*2 > letters:
+		return libwara.LanguageChineseSimplified
+	case scripts["cyrillic"]*2 > letters:
+		return libwara.LanguageRussian
+	}
+
+	// Out of place distance between the text and each profile, the closest profile wins
+	textProfile := trigramProfile(text)
+	best, bestDistance := d.Fallback, -1
+	for lang := libwara.LanguageJapanese; lang <= libwara.LanguageChineseTraditional; lang++ {
+		profile, ok := d.profiles[lang]
+		if !ok {
+			continue
+		}
+		distance := 0
+		for g, rank := range textProfile {
+			if pRank, ok := profile[g]; ok {
+				distance += abs(rank - pRank)
+			} else {
+				distance += profileSize
+			}
+		}
+		if bestDistance < 0 || distance < bestDistance {
+			best, bestDistance = lang, distance
+		}
+	}
+
+	return best
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
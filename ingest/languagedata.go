@@ -0,0 +1,49 @@
+package ingest
+
+import "cornchip.com/libwara/v2"
+
+// Sample text the trigram profile of each language is built from
+var languageSamples = map[libwara.LanguageId]string{
+	libwara.LanguageEnglish: `the quick brown fox jumps over the lazy dog. this is what we have been waiting for and
+	I think it is one of the best games that I have played in a long time. does anyone know when the next update
+	will come out? they said that there would be more news about it this week, but nothing has happened yet.
+	what do you think about the new character? it would be nice if they could fix the problems with the online
+	mode before they add anything else. I just finished the game and I have to say that the ending was great.
+	my favourite part was the music in the last world, which was really something else. thanks for reading`,
+	libwara.LanguageFrench: `le renard brun rapide saute par-dessus le chien paresseux. c'est ce que nous attendions
+	et je pense que c'est un des meilleurs jeux auxquels j'ai joué depuis longtemps. est-ce que quelqu'un sait
+	quand la prochaine mise à jour va sortir? ils ont dit qu'il y aurait plus de nouvelles cette semaine, mais
+	rien ne s'est encore passé. qu'est-ce que vous pensez du nouveau personnage? ce serait bien qu'ils
+	corrigent les problèmes du mode en ligne avant d'ajouter autre chose. je viens de finir le jeu et je dois
+	dire que la fin était géniale. ma partie préférée était la musique du dernier monde. merci de m'avoir lu`,
+	libwara.LanguageGerman: `der schnelle braune fuchs springt über den faulen hund. darauf haben wir gewartet und
+	ich denke, dass es eines der besten spiele ist, die ich seit langer zeit gespielt habe. weiß jemand, wann
+	das nächste update erscheint? sie haben gesagt, dass es diese woche mehr neuigkeiten geben würde, aber
+	bisher ist nichts passiert. was haltet ihr von dem neuen charakter? es wäre schön, wenn sie die probleme
+	mit dem online modus beheben würden, bevor sie etwas anderes hinzufügen. ich habe das spiel gerade beendet
+	und ich muss sagen, dass das ende toll war. mein lieblingsteil war die musik in der letzten welt. danke`,
+	libwara.LanguageItalian: `la rapida volpe marrone salta sopra il cane pigro. questo è quello che stavamo
+	aspettando e penso che sia uno dei migliori giochi a cui ho giocato da molto tempo. qualcuno sa quando
+	uscirà il prossimo aggiornamento? hanno detto che ci sarebbero state più notizie questa settimana, ma
+	non è ancora successo niente. cosa ne pensate del nuovo personaggio? sarebbe bello se sistemassero i
+	problemi della modalità online prima di aggiungere altro. ho appena finito il gioco e devo dire che il
+	finale era fantastico. la mia parte preferita era la musica dell'ultimo mondo. grazie per aver letto`,
+	libwara.LanguageSpanish: `el rápido zorro marrón salta sobre el perro perezoso. esto es lo que estábamos
+	esperando y creo que es uno de los mejores juegos que he jugado en mucho tiempo. ¿alguien sabe cuándo
+	saldrá la próxima actualización? dijeron que habría más noticias esta semana, pero todavía no ha pasado
+	nada. ¿qué os parece el nuevo personaje? estaría bien que arreglaran los problemas del modo en línea antes
+	de añadir otra cosa. acabo de terminar el juego y tengo que decir que el final fue genial. mi parte
+	favorita fue la música del último mundo, que era realmente otra cosa. gracias por leer`,
+	libwara.LanguageDutch: `de snelle bruine vos springt over de luie hond. hier hebben we op gewacht en ik denk
+	dat het een van de beste spellen is die ik in lange tijd heb gespeeld. weet iemand wanneer de volgende
+	update uitkomt? ze zeiden dat er deze week meer nieuws zou komen, maar er is nog niets gebeurd. wat vinden
+	jullie van het nieuwe personage? het zou fijn zijn als ze de problemen met de online modus oplossen
+	voordat ze iets anders toevoegen. ik heb het spel net uitgespeeld en ik moet zeggen dat het einde geweldig
+	was. mijn favoriete deel was de muziek in de laatste wereld. bedankt voor het lezen`,
+	libwara.LanguagePortuguese: `a rápida raposa marrom pula sobre o cão preguiçoso. isto é o que estávamos
+	esperando e acho que é um dos melhores jogos que joguei em muito tempo. alguém sabe quando vai sair a
+	próxima atualização? eles disseram que haveria mais notícias esta semana, mas ainda não aconteceu nada.
+	o que vocês acham do novo personagem? seria bom se eles corrigissem os problemas do modo online antes de
+	adicionar outra coisa. acabei de terminar o jogo e tenho que dizer que o final foi ótimo. a minha parte
+	favorita foi a música do último mundo, que era realmente outra coisa. obrigado por ler`,
+}
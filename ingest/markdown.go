@@ -0,0 +1,140 @@
+package ingest
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Reads Topics from a directory of Markdown files
+// Every subdirectory of Dir is a Topic, and every .md file in it an Item
+//
+// A file may start with front matter between "---" lines, with "key: value" lines for title, author, date
+// (YYYY-MM-DD or RFC 3339), score, flair, spoiler and nsfw. Without a title, the first "# " heading is used
+type MarkdownSource struct {
+	Dir string
+}
+
+func (s *MarkdownSource) Topics(ctx context.Context) ([]TopicConfig, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := []TopicConfig{}
+	for _, e := range entries {
+		if e.IsDir() {
+			ret = append(ret, TopicConfig{Name: e.Name(), Dir: filepath.Join(s.Dir, e.Name())})
+		}
+	}
+	return ret, nil
+}
+
+func (s *MarkdownSource) Posts(ctx context.Context, topic TopicConfig) ([]Item, error) {
+	paths, err := filepath.Glob(filepath.Join(topic.Dir, "*.md"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	ret := make([]Item, 0, len(paths))
+	for _, p := range paths {
+		item, err := readMarkdownItem(p, s.itemId(p))
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, item)
+	}
+	return ret, nil
+}
+
+// Returns the ID of a Markdown file, its path relative to Dir, or to the working directory if Dir is blank
+// File names alone are not enough, as files in different Topics may share a name
+func (s *MarkdownSource) itemId(path string) string {
+	base := s.Dir
+	if base == "" {
+		base = "."
+	}
+	if rel, err := filepath.Rel(base, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(path)
+}
+
+// Reads a Markdown file into an Item with the given ID
+func readMarkdownItem(path string, id string) (Item, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Item{}, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return Item{}, err
+	}
+
+	item := Item{
+		Id:        id,
+		Author:    "Anonymous",
+		CreatedAt: info.ModTime().UTC(),
+	}
+
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	if strings.HasPrefix(text, "---\n") {
+		if end := strings.Index(text[4:], "\n---"); end >= 0 {
+			applyFrontMatter(&item, text[4:4+end])
+			text = strings.TrimPrefix(text[4+end+4:], "\n")
+		}
+	}
+
+	if item.Title == "" {
+		scanner := bufio.NewScanner(strings.NewReader(text))
+		for scanner.Scan() {
+			line := scanner.Text()
+			if strings.HasPrefix(line, "# ") {
+				item.Title = strings.TrimSpace(line[2:])
+				text = strings.Replace(text, line, "", 1)
+				break
+			}
+		}
+	}
+	item.Body = strings.TrimSpace(text)
+
+	return item, nil
+}
+
+// Sets the fields of an Item from "key: value" front matter lines
+func applyFrontMatter(item *Item, frontMatter string) {
+	for _, line := range strings.Split(frontMatter, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "title":
+			item.Title = value
+		case "author":
+			item.Author = value
+		case "flair":
+			item.Flair = value
+		case "score":
+			item.Score, _ = strconv.Atoi(value)
+		case "spoiler":
+			item.Spoiler, _ = strconv.ParseBool(value)
+		case "nsfw":
+			item.NSFW, _ = strconv.ParseBool(value)
+		case "date":
+			if t, err := time.Parse(time.RFC3339, value); err == nil {
+				item.CreatedAt = t.UTC()
+			} else if t, err := time.Parse("2006-01-02", value); err == nil {
+				item.CreatedAt = t
+			}
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package ingest
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMarkdownItemIds(t *testing.T) {
+	dir := t.TempDir()
+	for _, topic := range []string{"first", "second"} {
+		if err := os.Mkdir(filepath.Join(dir, topic), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		text := "---\ntitle: " + topic + "\nauthor: someone\n---\nbody"
+		if err := os.WriteFile(filepath.Join(dir, topic, "post.md"), []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	src := &MarkdownSource{Dir: dir}
+	topics, err := src.Topics(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(topics) != 2 {
+		t.Fatalf("got %d Topics", len(topics))
+	}
+	ids := map[string]bool{}
+	for _, topic := range topics {
+		items, err := src.Posts(context.Background(), topic)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(items) != 1 || items[0].Title != topic.Name || items[0].Author != "someone" || items[0].Body != "body" {
+			t.Fatalf("got %+v", items)
+		}
+		if want := topic.Name + "/post.md"; items[0].Id != want {
+			t.Errorf("got ID %q, want %q", items[0].Id, want)
+		}
+		ids[items[0].Id] = true
+	}
+	if len(ids) != 2 {
+		t.Errorf("files with the same name in different Topics share an ID")
+	}
+
+	// Topics from the config have no source directory
+	items, err := (&MarkdownSource{}).Posts(context.Background(), TopicConfig{Dir: filepath.Join(dir, "first")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || filepath.Base(items[0].Id) != "post.md" || items[0].Id == "post.md" {
+		t.Errorf("got ID %q", items[0].Id)
+	}
+}
@@ -0,0 +1,80 @@
+package ingest
+
+import (
+	"log"
+	"os"
+
+	"cornchip.com/libwara/v2"
+)
+
+// Longest name a Mii can have
+const maxMiiName = 10
+
+// Returns the encoded Mii for an author, creating it on first use
+// The same author always gets the same Mii, across runs if the Builder has a History
+// Safe to call from several goroutines
+func (b *Builder) authorMii(author string) string {
+	b.miisMu.Lock()
+	mii, ok := b.miis[author]
+	b.miisMu.Unlock()
+	if ok {
+		return mii
+	}
+	if b.History != nil {
+		if stored, err := b.History.Mii(author); err == nil && stored != "" {
+			b.cacheMii(author, stored)
+			return stored
+		}
+	}
+
+	m, err := NewAuthorMii(author)
+	if err != nil {
+		return ""
+	}
+
+	mii = m.Encode()
+	if b.History != nil {
+		if err := b.History.SetMii(author, mii); err != nil {
+			log.Println("could not store the Mii of " + author + ": " + err.Error())
+		}
+	}
+	b.cacheMii(author, mii)
+	return mii
+}
+
+// Keeps the encoded Mii of an author for the rest of the run
+func (b *Builder) cacheMii(author string, mii string) {
+	b.miisMu.Lock()
+	defer b.miisMu.Unlock()
+	if b.miis == nil {
+		b.miis = map[string]string{}
+	}
+	b.miis[author] = mii
+}
+
+// Creates the Mii an author gets when no other Mii is known for them
+func NewAuthorMii(author string) (*libwara.Mii, error) {
+	name := []rune(author)
+	if len(name) > maxMiiName {
+		name = name[:maxMiiName]
+	}
+	return libwara.CreateRandomMii(author, string(name), "Reddit")
+}
+
+// Makes Posts by an author use a Mii, instead of a generated or stored one
+func (b *Builder) SetMii(author string, m *libwara.Mii) {
+	b.cacheMii(author, m.Encode())
+}
+
+// Reads a Mii from a PNG or JPEG image of a Mii QR code
+func ReadMiiQR(path string) (*libwara.Mii, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	img, err := libwara.ReadImage(data)
+	if err != nil {
+		return nil, err
+	}
+	return libwara.ImportMiiQR(img)
+}
@@ -0,0 +1,173 @@
+package ingest
+
+import (
+	"log"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// What happens to an Item containing a blocked word
+type BlockAction int
+
+const (
+	BlockDrop    BlockAction = iota // Leave the Item out
+	BlockMask                       // Replace the word with asterisks
+	BlockSpoiler                    // Keep the Item, but mark it as a spoiler
+)
+
+// Rules deciding which Items become Posts
+type Moderation struct {
+	AllowNSFW     bool        // Keep Items marked as NSFW
+	Blocklist     []string    // Words matched case insensitively against the title and body
+	BlockAction   BlockAction // What happens to Items containing a blocked word
+	MinScore      *int        // Drop Items scoring below this, nil disables the check
+	MaxScore      *int        // Drop Items scoring above this, nil disables the check
+	BannedAuthors []string    // Drop Items by these authors, ignoring case
+	MaxPerAuthor  int         // Keep at most this many Items per author, 0 disables the check
+	Logger        *log.Logger // Where removals are logged, nil uses the standard logger
+
+	blockPattern *regexp.Regexp
+	blockSource  string
+}
+
+// Moderation that only drops NSFW Items
+var DefaultModeration = Moderation{}
+
+// Logs why an Item was removed
+func (m *Moderation) logRemoval(item *Item, reason string) {
+	msg := "removed " + item.Id + " by " + item.Author + ": " + reason
+	if m.Logger != nil {
+		m.Logger.Println(msg)
+		return
+	}
+	log.Println(msg)
+}
+
+// Builds the regular expression matching any blocked word, rebuilding it when the Blocklist changes
+func (m *Moderation) blocked() *regexp.Regexp {
+	if len(m.Blocklist) == 0 {
+		return nil
+	}
+
+	source := strings.Join(m.Blocklist, "\x00")
+	if m.blockPattern != nil && m.blockSource == source {
+		return m.blockPattern
+	}
+
+	quoted := make([]string, 0, len(m.Blocklist))
+	for _, w := range m.Blocklist {
+		if w != "" {
+			quoted = append(quoted, regexp.QuoteMeta(w))
+		}
+	}
+	if len(quoted) == 0 {
+		return nil
+	}
+	// \b only knows ASCII letters, so the end of a word is matched as any character that is not a letter, digit or
+	// underscore in any script. RE2 has no lookbehind, the start is checked by blockedWords
+	m.blockPattern = regexp.MustCompile(`(?i)(` + strings.Join(quoted, "|") + `)(?:[^\p{L}\p{N}_]|$)`)
+	m.blockSource = source
+	return m.blockPattern
+}
+
+// Checks if a rune is part of a word
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsNumber(r)
+}
+
+// Returns the start and end of every blocked word in text that is not part of a longer word
+func blockedWords(pattern *regexp.Regexp, text string) [][2]int {
+	ret := [][2]int{}
+	for pos := 0; pos < len(text); {
+		loc := pattern.FindStringSubmatchIndex(text[pos:])
+		if loc == nil {
+			break
+		}
+		start, end := pos+loc[2], pos+loc[3]
+		if before, _ := utf8.DecodeLastRuneInString(text[:start]); start > 0 && isWordRune(before) {
+			// Inside a longer word, try again from the next character
+			_, size := utf8.DecodeRuneInString(text[start:])
+			pos = start + size
+			continue
+		}
+		ret = append(ret, [2]int{start, end})
+		pos = end
+	}
+	return ret
+}
+
+// Replaces every blocked word with asterisks
+func maskWords(pattern *regexp.Regexp, text string) string {
+	b := strings.Builder{}
+	last := 0
+	for _, w := range blockedWords(pattern, text) {
+		b.WriteString(text[last:w[0]])
+		b.WriteString(strings.Repeat("*", utf8.RuneCountInString(text[w[0]:w[1]])))
+		last = w[1]
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// Checks if text contains a blocked word
+func hasBlockedWord(pattern *regexp.Regexp, text string) bool {
+	return len(blockedWords(pattern, text)) > 0
+}
+
+// Applies the rules to a list of Items, returning the Items that are kept
+// Kept Items may have been changed, so the Items are copied rather than changed in place
+func (m *Moderation) Filter(items []Item) []Item {
+	banned := map[string]bool{}
+	for _, a := range m.BannedAuthors {
+		banned[strings.ToLower(a)] = true
+	}
+	perAuthor := map[string]int{}
+	pattern := m.blocked()
+
+	ret := make([]Item, 0, len(items))
+	for _, item := range items {
+		if item.NSFW && !m.AllowNSFW {
+			m.logRemoval(&item, "marked NSFW")
+			continue
+		}
+		if banned[strings.ToLower(item.Author)] {
+			m.logRemoval(&item, "banned author")
+			continue
+		}
+		if m.MinScore != nil && item.Score < *m.MinScore {
+			m.logRemoval(&item, "score below minimum")
+			continue
+		}
+		if m.MaxScore != nil && item.Score > *m.MaxScore {
+			m.logRemoval(&item, "score above maximum")
+			continue
+		}
+
+		if pattern != nil && (hasBlockedWord(pattern, item.Title) || hasBlockedWord(pattern, item.Body)) {
+			switch m.BlockAction {
+			case BlockDrop:
+				m.logRemoval(&item, "contains blocked word")
+				continue
+			case BlockMask:
+				item.Title = maskWords(pattern, item.Title)
+				item.Body = maskWords(pattern, item.Body)
+			case BlockSpoiler:
+				item.Spoiler = true
+			}
+		}
+
+		if m.MaxPerAuthor > 0 {
+			if perAuthor[item.Author] >= m.MaxPerAuthor {
+				m.logRemoval(&item, "author post quota reached")
+				continue
+			}
+			perAuthor[item.Author]++
+		}
+
+		ret = append(ret, item)
+	}
+
+	return ret
+}
@@ -0,0 +1,47 @@
+package ingest
+
+import (
+	"io"
+	"log"
+	"testing"
+)
+
+func TestModerationBlocklist(t *testing.T) {
+	tests := []struct {
+		name      string
+		blocklist []string
+		in        string
+		masked    string
+	}{
+		{"word", []string{"bad"}, "a bad day", "a *** day"},
+		{"ignores case", []string{"bad"}, "BAD news", "*** news"},
+		{"inside word", []string{"bad"}, "badge and abad", "badge and abad"},
+		{"repeated", []string{"bad"}, "bad bad,bad", "*** ***,***"},
+		{"inside then alone", []string{"bad"}, "badbad bad", "badbad ***"},
+		{"accented word", []string{"café"}, "a café!", "a ****!"},
+		{"accented neighbour", []string{"pok"}, "Pokémon", "Pokémon"},
+		{"letter before", []string{"mon"}, "Pokémon", "Pokémon"},
+		{"cyrillic", []string{"плохо"}, "это плохо, плохой", "это *****, плохой"},
+		{"cjk", []string{"日本"}, "日本 and 日本語", "** and 日本語"},
+		{"digits", []string{"42"}, "42 but not 420", "** but not 420"},
+		{"underscore", []string{"bad"}, "not_bad bad_", "not_bad bad_"},
+		{"phrase", []string{"very bad"}, "so very bad.", "so ********."},
+		{"longer alternative fails", []string{"a b", "a"}, "a bc", "* bc"},
+		{"blank word", []string{""}, "anything", "anything"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := Moderation{Blocklist: tt.blocklist, BlockAction: BlockMask, Logger: log.New(io.Discard, "", 0)}
+			got := m.Filter([]Item{{Id: "1", Body: tt.in}})
+			if len(got) != 1 || got[0].Body != tt.masked {
+				t.Fatalf("got %+v, want body %q", got, tt.masked)
+			}
+
+			m.BlockAction = BlockDrop
+			kept := len(m.Filter([]Item{{Id: "1", Body: tt.in}})) == 1
+			if blocked := tt.in != tt.masked; kept == blocked {
+				t.Errorf("kept %v, want %v", kept, !blocked)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Credentials of a Reddit script app
+// Without a Username the client credentials grant is used, with one the password grant
+type OAuthConfig struct {
+	ClientId     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	Username     string `json:"username,omitempty"`
+	Password     string `json:"password,omitempty"`
+	TokenURL     string `json:"token_url,omitempty"` // "https://www.reddit.com/api/v1/access_token" if blank
+}
+
+// Base URL of the API once authenticated
+const oauthBaseURL = "https://oauth.reddit.com"
+
+const defaultTokenURL = "https://www.reddit.com/api/v1/access_token"
+
+// An access token and when it stops working
+type oauthToken struct {
+	mu      sync.Mutex
+	value   string
+	expires time.Time
+}
+
+// Response of the access token endpoint
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+	Error       string `json:"error"`
+}
+
+// Creates a Client that authenticates as a script app
+func NewOAuthClient(config OAuthConfig) *Client {
+	c := NewClient()
+	c.BaseURL = oauthBaseURL
+	c.OAuth = &config
+	return c
+}
+
+// Returns a valid access token, requesting a new one if there is none or it is about to expire
+func (c *Client) accessToken(ctx context.Context) (string, error) {
+	c.token.mu.Lock()
+	defer c.token.mu.Unlock()
+
+	if c.token.value != "" && c.now().Add(time.Minute).Before(c.token.expires) {
+		return c.token.value, nil
+	}
+
+	form := url.Values{}
+	if c.OAuth.Username == "" {
+		form.Set("grant_type", "client_credentials")
+	} else {
+		form.Set("grant_type", "password")
+		form.Set("username", c.OAuth.Username)
+		form.Set("password", c.OAuth.Password)
+	}
+
+	tokenURL := c.OAuth.TokenURL
+	if tokenURL == "" {
+		tokenURL = defaultTokenURL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(c.OAuth.ClientId, c.OAuth.ClientSecret)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", c.UserAgent)
+
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.New("requesting access token: " + resp.Status)
+	}
+
+	t := tokenResponse{}
+	if err = json.NewDecoder(resp.Body).Decode(&t); err != nil {
+		return "", err
+	}
+	if t.Error != "" {
+		return "", errors.New("requesting access token: " + t.Error)
+	}
+	if t.AccessToken == "" {
+		return "", errors.New("requesting access token: no token in response")
+	}
+
+	c.token.value = t.AccessToken
+	c.token.expires = c.now().Add(time.Duration(t.ExpiresIn) * time.Second)
+	return c.token.value, nil
+}
+
+// Forgets the access token, so the next request gets a new one
+func (c *Client) dropToken() {
+	c.token.mu.Lock()
+	c.token.value = ""
+	c.token.mu.Unlock()
+}
@@ -0,0 +1,134 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// Reads a Topic from a Mastodon or other ActivityPub outbox, one Item per public note
+type OutboxSource struct {
+	Client    *Client
+	TopicList []TopicConfig // Topics with their Outbox set
+}
+
+// An ActivityPub OrderedCollection or OrderedCollectionPage
+// first is a link to the first page, or the page itself
+type outboxCollection struct {
+	Type         string            `json:"type"`
+	First        json.RawMessage   `json:"first"`
+	OrderedItems []json.RawMessage `json:"orderedItems"`
+}
+
+// A Create activity wrapping a Note
+type outboxActivity struct {
+	Type   string          `json:"type"`
+	Actor  string          `json:"actor"`
+	Object json.RawMessage `json:"object"`
+}
+
+// An ActivityPub Note, as posted by Mastodon
+type outboxNote struct {
+	Id           string `json:"id"`
+	Type         string `json:"type"`
+	Summary      string `json:"summary"` // Content warning
+	Content      string `json:"content"`
+	Published    string `json:"published"`
+	AttributedTo string `json:"attributedTo"`
+	Sensitive    bool   `json:"sensitive"`
+	InReplyTo    string `json:"inReplyTo"`
+	Attachment   []struct {
+		MediaType string `json:"mediaType"`
+		Url       string `json:"url"`
+	} `json:"attachment"`
+}
+
+func (s *OutboxSource) Topics(ctx context.Context) ([]TopicConfig, error) {
+	return s.TopicList, nil
+}
+
+func (s *OutboxSource) Posts(ctx context.Context, topic TopicConfig) ([]Item, error) {
+	collection, err := s.collection(ctx, topic.Outbox)
+	if err != nil {
+		return nil, err
+	}
+
+	// Mastodon only lists the items on the pages, the collection links to the first one
+	if len(collection.OrderedItems) == 0 && len(collection.First) > 0 {
+		first := outboxCollection{}
+		link := ""
+		if json.Unmarshal(collection.First, &link) == nil {
+			if first, err = s.collection(ctx, link); err != nil {
+				return nil, err
+			}
+		} else if err = json.Unmarshal(collection.First, &first); err != nil {
+			return nil, err
+		}
+		collection = first
+	}
+
+	return outboxItems(collection.OrderedItems), nil
+}
+
+// Fetches a collection or collection page
+func (s *OutboxSource) collection(ctx context.Context, rawURL string) (outboxCollection, error) {
+	c := outboxCollection{}
+	data, err := s.Client.fetchAs(ctx, rawURL, "application/activity+json")
+	if err != nil {
+		return c, err
+	}
+	err = json.Unmarshal(data, &c)
+	return c, err
+}
+
+// Turns the Create activities of an outbox into Items. Boosts, replies and other activities are left out
+func outboxItems(activities []json.RawMessage) []Item {
+	ret := []Item{}
+	for _, raw := range activities {
+		a := outboxActivity{}
+		if json.Unmarshal(raw, &a) != nil || a.Type != "Create" {
+			continue
+		}
+		note := outboxNote{}
+		if json.Unmarshal(a.Object, &note) != nil || note.Type != "Note" || note.InReplyTo != "" {
+			continue
+		}
+
+		author := note.AttributedTo
+		if author == "" {
+			author = a.Actor
+		}
+		created, _ := time.Parse(time.RFC3339, note.Published)
+
+		item := Item{
+			Id:        note.Id,
+			Author:    actorName(author),
+			Title:     note.Summary,
+			Body:      htmlToText(note.Content),
+			CreatedAt: created.UTC(),
+			NSFW:      note.Sensitive && note.Summary == "",
+			Spoiler:   note.Summary != "",
+		}
+		for _, att := range note.Attachment {
+			if strings.HasPrefix(att.MediaType, "image/") {
+				item.ImageURL = att.Url
+				break
+			}
+		}
+		ret = append(ret, item)
+	}
+
+	return ret
+}
+
+// Returns the username of an actor from its URL, "https://example.social/users/alice" becomes "alice"
+func actorName(actor string) string {
+	u, err := url.Parse(actor)
+	if err != nil || u.Path == "" {
+		return actor
+	}
+	return strings.TrimPrefix(path.Base(u.Path), "@")
+}
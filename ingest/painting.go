@@ -0,0 +1,32 @@
+package ingest
+
+import (
+	"context"
+
+	"cornchip.com/libwara/v2"
+)
+
+// Downloads the image of an Item and stores it in a Post as a painting
+func (b *Builder) addPainting(ctx context.Context, post *libwara.Post, item *Item) error {
+	data, err := b.Client.fetch(ctx, item.ImageURL)
+	if err != nil {
+		return err
+	}
+
+	img, err := libwara.ReadImage(data)
+	if err != nil {
+		return err
+	}
+
+	content, size, err := libwara.CreatePainting(img, b.Dither)
+	if err != nil {
+		return err
+	}
+
+	post.PaintingFormat = "tga"
+	post.PaintingContent = content
+	post.PaintingSize = size
+	post.Body = b.Formatter.Caption(item.Title)
+
+	return nil
+}
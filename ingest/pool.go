@@ -0,0 +1,55 @@
+package ingest
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// Returns the number of workers the Builder runs at once
+func (b *Builder) workers() int {
+	if b.Workers > 0 {
+		return b.Workers
+	}
+	return runtime.NumCPU()
+}
+
+// Calls fn for every index from 0 to n-1 on up to b.workers() goroutines, and waits for every call to return
+// Each call must only touch the results of its own index, so the output does not depend on scheduling
+// Stops handing out indices once the context is done and returns its error
+func (b *Builder) parallel(ctx context.Context, n int, fn func(ctx context.Context, i int)) error {
+	workers := b.workers()
+	if workers > n {
+		workers = n
+	}
+
+	indices := make(chan int)
+	wg := sync.WaitGroup{}
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				fn(ctx, i)
+			}
+		}()
+	}
+
+	var err error
+feed:
+	for i := 0; i < n; i++ {
+		select {
+		case indices <- i:
+		case <-ctx.Done():
+			err = ctx.Err()
+			break feed
+		}
+	}
+	close(indices)
+	wg.Wait()
+
+	if err == nil {
+		err = ctx.Err()
+	}
+	return err
+}
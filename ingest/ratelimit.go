@@ -0,0 +1,89 @@
+package ingest
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Reddit's rate limit, as reported by the X-Ratelimit-* headers of the last response
+type rateLimit struct {
+	mu        sync.Mutex
+	known     bool
+	remaining float64
+	reset     time.Time
+}
+
+// Records the rate limit headers of a response
+func (c *Client) updateRateLimit(h http.Header) {
+	remaining, err := strconv.ParseFloat(h.Get("X-Ratelimit-Remaining"), 64)
+	if err != nil {
+		return
+	}
+	reset, err := strconv.ParseFloat(h.Get("X-Ratelimit-Reset"), 64)
+	if err != nil {
+		return
+	}
+
+	c.limit.mu.Lock()
+	c.limit.known = true
+	c.limit.remaining = remaining
+	c.limit.reset = c.now().Add(time.Duration(reset * float64(time.Second)))
+	c.limit.mu.Unlock()
+}
+
+// Waits until a request is allowed by the rate limit
+// A request is counted against the limit as soon as it is allowed, so concurrent callers do not overrun it
+// Once the limit is used up every caller waits for the reset, until the headers of a response restore it
+func (c *Client) waitRateLimit(ctx context.Context) error {
+	c.limit.mu.Lock()
+	var wait time.Duration
+	if c.limit.known {
+		if c.limit.remaining < 1 {
+			wait = c.limit.reset.Sub(c.now())
+		} else {
+			c.limit.remaining--
+		}
+	}
+	c.limit.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+	return c.sleep(ctx, wait)
+}
+
+// Returns how long to wait before retrying a failed request
+// Retry-After is used when the server sends it, otherwise the delay doubles with every attempt
+func (c *Client) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs >= 0 {
+			return time.Duration(secs) * time.Second
+		}
+	}
+
+	delay := c.RetryDelay << attempt
+	if c.MaxRetryDelay > 0 && delay > c.MaxRetryDelay {
+		delay = c.MaxRetryDelay
+	}
+	return delay
+}
+
+// Checks if a request that got this status should be tried again
+func retryable(status int) bool {
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+// Sleeps for a duration, giving up early if the context is cancelled
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
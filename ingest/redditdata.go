@@ -0,0 +1,82 @@
+package ingest
+
+// Seconds since the Unix epoch. Reddit sends a number, some Pushshift dumps a string
+type unixTime float64
+
+// A Reddit submission, as returned in the data field of a t3 thing
+type Submission struct {
+	Id          string   `json:"id"`
+	Name        string   `json:"name"` // Fullname, "t3_" followed by the ID
+	Subreddit   string   `json:"subreddit"`
+	Author      string   `json:"author"`
+	Title       string   `json:"title"`
+	Selftext    string   `json:"selftext"`
+	Score       int      `json:"score"`
+	NumComments int      `json:"num_comments"`
+	CreatedUtc  unixTime `json:"created_utc"`
+	Permalink   string   `json:"permalink"`
+	Url         string   `json:"url"`
+	Flair       string   `json:"link_flair_text"`
+	UpvoteRatio float64  `json:"upvote_ratio"`
+	Over18      bool     `json:"over_18"`
+	Spoiler     bool     `json:"spoiler"`
+	PostHint    string   `json:"post_hint"`
+	Preview     struct {
+		Images []struct {
+			Source struct {
+				Url    string `json:"url"`
+				Width  int    `json:"width"`
+				Height int    `json:"height"`
+			} `json:"source"`
+		} `json:"images"`
+	} `json:"preview"`
+}
+
+// A Reddit thing wrapping a Submission
+type submissionThing struct {
+	Kind string     `json:"kind"`
+	Data Submission `json:"data"`
+}
+
+// A Reddit listing of submissions, like the one returned by /r/<subreddit>/hot.json
+type Listing struct {
+	Kind string `json:"kind"`
+	Data struct {
+		After    string            `json:"after"`
+		Before   string            `json:"before"`
+		Children []submissionThing `json:"children"`
+	} `json:"data"`
+}
+
+// The data field of a t5 thing, as returned by /r/<subreddit>/about.json
+type SubredditAbout struct {
+	Name                  string `json:"display_name"`
+	Title                 string `json:"title"`
+	IconImg               string `json:"icon_img"`
+	CommunityIcon         string `json:"community_icon"`
+	BannerImg             string `json:"banner_img"`
+	BannerBackgroundImage string `json:"banner_background_image"`
+	Over18                bool   `json:"over18"`
+}
+
+// A Reddit comment, as returned in the data field of a t1 thing
+type Comment struct {
+	Id         string         `json:"id"`
+	Name       string         `json:"name"` // Fullname, "t1_" followed by the ID
+	ParentId   string         `json:"parent_id"`
+	Author     string         `json:"author"`
+	Body       string         `json:"body"`
+	Score      int            `json:"score"`
+	CreatedUtc unixTime       `json:"created_utc"`
+	Replies    commentListing `json:"replies"`
+}
+
+// A Reddit listing of comments. Reddit sends a blank string instead of a listing when there are no replies
+type commentListing struct {
+	Data struct {
+		Children []struct {
+			Kind string  `json:"kind"`
+			Data Comment `json:"data"`
+		} `json:"children"`
+	} `json:"data"`
+}
@@ -0,0 +1,102 @@
+package ingest
+
+import (
+	"bytes"
+	"encoding/json"
+	"html"
+	"io"
+	"strconv"
+	"time"
+)
+
+func (t *unixTime) UnmarshalJSON(data []byte) error {
+	data = bytes.Trim(data, `"`)
+	if len(data) == 0 || string(data) == "null" {
+		*t = 0
+		return nil
+	}
+	f, err := strconv.ParseFloat(string(data), 64)
+	if err != nil {
+		return err
+	}
+	*t = unixTime(f)
+	return nil
+}
+
+// Returns the time in UTC
+func (t unixTime) Time() time.Time {
+	return time.Unix(int64(t), 0).UTC()
+}
+
+// Decodes a Reddit listing and returns the submissions in it
+func ParseListing(r io.Reader) ([]Submission, error) {
+	l := Listing{}
+	if err := json.NewDecoder(r).Decode(&l); err != nil {
+		return nil, err
+	}
+
+	return l.Submissions(), nil
+}
+
+// Returns the submissions in a listing
+func (l *Listing) Submissions() []Submission {
+	ret := make([]Submission, 0, len(l.Data.Children))
+	for _, c := range l.Data.Children {
+		if c.Kind != "t3" {
+			continue
+		}
+		ret = append(ret, c.Data)
+	}
+
+	return ret
+}
+
+// Returns the image URLs of a subreddit, best suited as an icon first
+func (a *SubredditAbout) IconURLs() []string {
+	ret := []string{}
+	for _, u := range []string{a.CommunityIcon, a.IconImg, a.BannerImg, a.BannerBackgroundImage} {
+		if u != "" {
+			ret = append(ret, html.UnescapeString(u))
+		}
+	}
+	return ret
+}
+
+// Converts a Submission to an Item
+func (s *Submission) Item() Item {
+	return Item{
+		Id:        s.Name,
+		Author:    s.Author,
+		Title:     s.Title,
+		Body:      s.Selftext,
+		Score:     s.Score,
+		Comments:  s.NumComments,
+		CreatedAt: s.CreatedUtc.Time(),
+
+		Flair:       s.Flair,
+		UpvoteRatio: s.UpvoteRatio,
+		NSFW:        s.Over18,
+		Spoiler:     s.Spoiler,
+		ImageURL:    s.ImageURL(),
+	}
+}
+
+// Returns the URL of the preview image of an image post, or a blank string for any other post
+func (s *Submission) ImageURL() string {
+	if s.PostHint != "image" {
+		return ""
+	}
+	if len(s.Preview.Images) > 0 && s.Preview.Images[0].Source.Url != "" {
+		return html.UnescapeString(s.Preview.Images[0].Source.Url)
+	}
+	return s.Url
+}
+
+// Converts a list of Submissions to Items
+func SubmissionItems(subs []Submission) []Item {
+	ret := make([]Item, len(subs))
+	for i := range subs {
+		ret[i] = subs[i].Item()
+	}
+	return ret
+}
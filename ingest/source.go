@@ -0,0 +1,163 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	"cornchip.com/libwara/v2"
+)
+
+// Somewhere Topics and their Posts come from
+type Source interface {
+	// Returns the Topics the source provides
+	Topics(ctx context.Context) ([]TopicConfig, error)
+	// Returns the Items of a Topic, best first
+	Posts(ctx context.Context, topic TopicConfig) ([]Item, error)
+}
+
+// Adds every Topic of a Source to the Nup
+func (b *Builder) Build(ctx context.Context, n *libwara.Nup, src Source) error {
+	topics, err := src.Topics(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, topic := range topics {
+		items, err := src.Posts(ctx, topic)
+		if err != nil {
+			return err
+		}
+		if err = b.AddTopic(ctx, n, topic, items); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Reads the Topics from Reddit's JSON API, one Topic per subreddit
+type RedditSource struct {
+	Client    *Client
+	TopicList []TopicConfig
+	Sort      string // "hot", "new", "top", ...
+	Limit     int    // Submissions fetched per Topic
+}
+
+func (s *RedditSource) Topics(ctx context.Context) ([]TopicConfig, error) {
+	return s.TopicList, nil
+}
+
+func (s *RedditSource) Posts(ctx context.Context, topic TopicConfig) ([]Item, error) {
+	if topic.Subreddit == "" {
+		return nil, errors.New("topic " + topic.Name + " has no subreddit")
+	}
+	subs, err := s.Client.Submissions(ctx, topic.Subreddit, s.Sort, s.Limit)
+	if err != nil {
+		return nil, err
+	}
+	return SubmissionItems(subs), nil
+}
+
+// Reads the Topics from a Pushshift style dump, one Topic per subreddit
+// The dump is read once, on the first call to Posts
+type DumpSource struct {
+	Path      string
+	Filter    DumpFilter
+	TopicList []TopicConfig
+	PerTopic  int // Best scoring submissions kept per Topic
+
+	once  sync.Once
+	items map[string][]Item
+	err   error
+}
+
+func (s *DumpSource) Topics(ctx context.Context) ([]TopicConfig, error) {
+	return s.TopicList, nil
+}
+
+func (s *DumpSource) Posts(ctx context.Context, topic TopicConfig) ([]Item, error) {
+	s.once.Do(func() {
+		dump, err := OpenDump(s.Path)
+		if err != nil {
+			s.err = err
+			return
+		}
+		defer dump.Close()
+		s.items, s.err = CollectDump(dump, s.Filter, s.PerTopic)
+	})
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	return s.items[strings.ToLower(topic.Subreddit)], nil
+}
+
+// Submissions read per subreddit when a History is configured, the most Reddit returns in one listing
+const historyFetchLimit = 100
+
+// Sends each Topic of a Config to the Source for its kind: a feed, an outbox, a Markdown directory or a subreddit
+type configSource struct {
+	topics    []TopicConfig
+	subreddit Source
+	feed      Source
+	outbox    Source
+	markdown  Source
+}
+
+// Returns a Source for every Topic in the Config
+// Subreddits are read from the dump when one is configured, otherwise from Reddit through the client
+// With a History more submissions are read than fit in a Topic, so there are fresh ones to prefer
+func (c *Config) Source(client *Client) (Source, error) {
+	limit := int(c.Limits.MaxPostsPerTopic)
+	if limit == 0 {
+		limit = int(libwara.MAX_POSTS_PER_TOPIC)
+	}
+	if c.History != "" && limit < historyFetchLimit {
+		limit = historyFetchLimit
+	}
+
+	var subreddit Source = &RedditSource{
+		Client:    client,
+		TopicList: c.Topics,
+		Sort:      "hot",
+		Limit:     limit,
+	}
+	if c.Dump != nil {
+		filter, err := c.DumpFilter()
+		if err != nil {
+			return nil, err
+		}
+		subreddit = &DumpSource{
+			Path:      c.Dump.Path,
+			Filter:    filter,
+			TopicList: c.Topics,
+			PerTopic:  limit,
+		}
+	}
+
+	return &configSource{
+		topics:    c.Topics,
+		subreddit: subreddit,
+		feed:      &FeedSource{Client: client},
+		outbox:    &OutboxSource{Client: client},
+		markdown:  &MarkdownSource{},
+	}, nil
+}
+
+func (s *configSource) Topics(ctx context.Context) ([]TopicConfig, error) {
+	return s.topics, nil
+}
+
+func (s *configSource) Posts(ctx context.Context, topic TopicConfig) ([]Item, error) {
+	switch {
+	case topic.Feed != "":
+		return s.feed.Posts(ctx, topic)
+	case topic.Outbox != "":
+		return s.outbox.Posts(ctx, topic)
+	case topic.Dir != "":
+		return s.markdown.Posts(ctx, topic)
+	}
+	return s.subreddit.Posts(ctx, topic)
+}
@@ -0,0 +1,83 @@
+package ingest
+
+import "math"
+
+// Maps a raw count from the source to a count shown on the console
+type ScaleFunc func(raw int) int
+
+// Scales linearly by a factor
+func LinearScale(factor float64) ScaleFunc {
+	return func(raw int) int {
+		return int(math.Round(float64(raw) * factor))
+	}
+}
+
+// Scales logarithmically, so huge counts do not dwarf small ones
+// A raw count of 9 becomes factor, 99 becomes 2*factor, and so on
+func LogScale(factor float64) ScaleFunc {
+	return func(raw int) int {
+		if raw <= 0 {
+			return 0
+		}
+		return int(math.Round(math.Log10(float64(raw)+1) * factor))
+	}
+}
+
+// Configuration for the Topic and Post statistics
+type StatsConfig struct {
+	Empathy    ScaleFunc // Maps the summed score of a topic, or the score of a post, to its empathy count
+	Replies    ScaleFunc // Maps the comment count of an item to the reply count of its post
+	MaxEmpathy int       // Cap on the empathy count of a topic
+	MaxReplies int       // Cap on the reply count of a post
+}
+
+// Statistics that look plausible on the console
+var DefaultStats = StatsConfig{
+	Empathy:    LogScale(250),
+	Replies:    LinearScale(1),
+	MaxEmpathy: 9999,
+	MaxReplies: 999,
+}
+
+// Applies a ScaleFunc and clamps the result to [0, max]
+func (c *StatsConfig) apply(f ScaleFunc, raw, max int) int {
+	if f == nil {
+		f = LinearScale(1)
+	}
+	val := f(raw)
+	if val < 0 {
+		val = 0
+	}
+	if max > 0 && val > max {
+		val = max
+	}
+	return val
+}
+
+// Returns the empathy count for a topic with a summed score, or for a post with a score
+func (c *StatsConfig) EmpathyCount(score int) int {
+	return c.apply(c.Empathy, score, c.MaxEmpathy)
+}
+
+// Returns the reply count for a post with a comment count
+func (c *StatsConfig) ReplyCount(comments int) int {
+	return c.apply(c.Replies, comments, c.MaxReplies)
+}
+
+// Counts the unique authors in a list of Items
+func uniqueAuthors(items []Item) uint {
+	seen := map[string]bool{}
+	for i := range items {
+		seen[items[i].Author] = true
+	}
+	return uint(len(seen))
+}
+
+// Sums the scores of a list of Items
+func totalScore(items []Item) int {
+	total := 0
+	for i := range items {
+		total += items[i].Score
+	}
+	return total
+}
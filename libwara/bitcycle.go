@@ -27,7 +27,7 @@ func (b *bitCycle) readBitCycle(l uint8) uint64 {
 		byteindex := uint64(math.Floor(float64(b.index) / 8))
 
 		mask := byte(0x01) << byte(bitindex)
-		ret |= uint64(b.bits[byteindex]&mask) << i
+		ret |= uint64((b.bits[byteindex]&mask)>>bitindex) << i
 
 		b.index++
 		if b.index >= b.size {
@@ -0,0 +1,281 @@
+package libwara
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Order of the bytes of a multi-byte unit
+type ByteOrder int
+
+const (
+	LittleEndian ByteOrder = iota
+	BigEndian
+)
+
+// How the bits of a unit are numbered
+type BitOrder int
+
+const (
+	LSBFirst BitOrder = iota // Bit 0 is the least significant bit of the unit, as on the 3DS and Wii U
+	MSBFirst                 // Bit 0 is the most significant bit of the unit, as on the Wii
+)
+
+// What a Field holds
+type FieldKind int
+
+const (
+	Unsigned FieldKind = iota
+	Signed             // Two's complement
+	Bytes              // Raw bytes, such as a UTF-16 name. Must start on a byte and be a whole number of bytes
+	Reserved           // Unused bits, kept at zero by ClearReserved
+)
+
+// A value packed into a binary structure
+// A Field lives in a unit of one or more bytes that are read together as one integer in the Field's byte order.
+// Bits are numbered within that integer as the BitOrder of the Layout says
+type Field struct {
+	Name   string
+	Offset uint      // First byte of the unit
+	Bit    uint      // First bit of the Field within the unit
+	Size   uint      // Number of bits
+	Unit   uint      // Bytes in the unit, at most 8. 0 uses just enough bytes to hold the Field
+	Order  ByteOrder // Byte order of the unit
+	Kind   FieldKind
+	Min    int64 // Smallest allowed value
+	Max    int64 // Largest allowed value. If Max is 0 and Min is not negative, every value from Min up that fits is allowed
+}
+
+// A binary structure described field by field
+type Layout struct {
+	Name     string
+	Size     uint // Bytes in the structure
+	BitOrder BitOrder
+	Fields   []Field
+}
+
+// Returns the number of bytes in the unit of a Field
+func (f *Field) unitSize() uint {
+	if f.Unit != 0 {
+		return f.Unit
+	}
+	return (f.Bit + f.Size + 7) / 8
+}
+
+// Returns a mask of the low Size bits
+func (f *Field) mask() uint64 {
+	if f.Size >= 64 {
+		return ^uint64(0)
+	}
+	return (uint64(1) << f.Size) - 1
+}
+
+// Returns the position of the lowest bit of a Field within its unit, counted from the least significant bit
+func (l *Layout) shift(f *Field) uint {
+	if l.BitOrder == MSBFirst {
+		return f.unitSize()*8 - f.Bit - f.Size
+	}
+	return f.Bit
+}
+
+// Reads the unit of a Field as an integer
+func readUnit(data []byte, f *Field) uint64 {
+	n := f.unitSize()
+	ret := uint64(0)
+	for i := uint(0); i < n; i++ {
+		b := uint64(data[f.Offset+i])
+		if f.Order == BigEndian {
+			ret |= b << ((n - 1 - i) * 8)
+		} else {
+			ret |= b << (i * 8)
+		}
+	}
+	return ret
+}
+
+// Writes the unit of a Field from an integer
+func writeUnit(data []byte, f *Field, unit uint64) {
+	n := f.unitSize()
+	for i := uint(0); i < n; i++ {
+		if f.Order == BigEndian {
+			data[f.Offset+i] = byte(unit >> ((n - 1 - i) * 8))
+		} else {
+			data[f.Offset+i] = byte(unit >> (i * 8))
+		}
+	}
+}
+
+// Returns the Field with a name
+func (l *Layout) Field(name string) (*Field, error) {
+	for i := range l.Fields {
+		if l.Fields[i].Name == name {
+			return &l.Fields[i], nil
+		}
+	}
+	return nil, errors.New(l.Name + " has no field " + name)
+}
+
+// Returns the raw bits of a Field. data must hold at least Size bytes
+func (l *Layout) Get(data []byte, f *Field) uint64 {
+	return (readUnit(data, f) >> l.shift(f)) & f.mask()
+}
+
+// Stores the raw bits of a Field, without checking the allowed range. Bits that do not fit are dropped
+func (l *Layout) Put(data []byte, f *Field, value uint64) {
+	shift := l.shift(f)
+	unit := readUnit(data, f)
+	unit &^= f.mask() << shift
+	unit |= (value & f.mask()) << shift
+	writeUnit(data, f, unit)
+}
+
+// Returns the value of a Signed Field, sign extended
+func (l *Layout) GetSigned(data []byte, f *Field) int64 {
+	raw := l.Get(data, f)
+	if f.Size < 64 && raw&(uint64(1)<<(f.Size-1)) != 0 {
+		raw |= ^f.mask()
+	}
+	return int64(raw)
+}
+
+// Checks if a Field limits its values to a range
+func (f *Field) ranged() bool {
+	return f.Min != 0 || f.Max != 0
+}
+
+// Checks if a Field limits how big its values are. A Max of 0 is only a limit when Min is negative
+func (f *Field) bounded() bool {
+	return f.Max != 0 || f.Min < 0
+}
+
+// Returns the error for a value outside the range of a Field
+func (f *Field) rangeError(value string) error {
+	var msg strings.Builder
+	msg.WriteString(f.Name)
+	if f.bounded() {
+		msg.WriteString(": expected value between ")
+		msg.WriteString(strconv.FormatInt(f.Min, 10))
+		msg.WriteString(" and ")
+		msg.WriteString(strconv.FormatInt(f.Max, 10))
+	} else {
+		msg.WriteString(": expected value of at least ")
+		msg.WriteString(strconv.FormatInt(f.Min, 10))
+	}
+	msg.WriteString(", got ")
+	msg.WriteString(value)
+	return errors.New(msg.String())
+}
+
+// Returns the error for a value too big for the bits of a Field
+func (f *Field) sizeError(value string) error {
+	return errors.New(f.Name + ": " + value + " does not fit in " + strconv.FormatUint(uint64(f.Size), 10) + " bits")
+}
+
+// Stores a value in an Unsigned Field, checking the allowed range
+func (l *Layout) Set(data []byte, f *Field, value uint64) error {
+	if f.Kind != Unsigned {
+		return errors.New(f.Name + " is not an unsigned field")
+	}
+	if value&^f.mask() != 0 {
+		return f.sizeError(strconv.FormatUint(value, 10))
+	}
+	if f.ranged() && (f.Min > 0 && value < uint64(f.Min) || f.bounded() && value > uint64(f.Max)) {
+		return f.rangeError(strconv.FormatUint(value, 10))
+	}
+	l.Put(data, f, value)
+	return nil
+}
+
+// Stores a value in a Signed Field, checking the allowed range
+func (l *Layout) SetSigned(data []byte, f *Field, value int64) error {
+	if f.Kind != Signed {
+		return errors.New(f.Name + " is not a signed field")
+	}
+	if f.Size < 64 && (value < -(int64(1)<<(f.Size-1)) || value >= int64(1)<<(f.Size-1)) {
+		return f.sizeError(strconv.FormatInt(value, 10))
+	}
+	if f.ranged() && (value < f.Min || f.bounded() && value > f.Max) {
+		return f.rangeError(strconv.FormatInt(value, 10))
+	}
+	l.Put(data, f, uint64(value))
+	return nil
+}
+
+// Returns a copy of the bytes of a Bytes Field
+func (l *Layout) GetBytes(data []byte, f *Field) []byte {
+	return append([]byte{}, data[f.Offset:f.Offset+f.Size/8]...)
+}
+
+// Stores the bytes of a Bytes Field, padded with zeros. Fails if there are too many bytes
+func (l *Layout) SetBytes(data []byte, f *Field, value []byte) error {
+	if uint(len(value)) > f.Size/8 {
+		return errors.New(f.Name + ": " + strconv.Itoa(len(value)) + " bytes do not fit in " + strconv.FormatUint(uint64(f.Size/8), 10))
+	}
+	field := data[f.Offset : f.Offset+f.Size/8]
+	for i := range field {
+		field[i] = 0
+	}
+	copy(field, value)
+	return nil
+}
+
+// Sets every Reserved Field to zero
+func (l *Layout) ClearReserved(data []byte) {
+	for i := range l.Fields {
+		if f := &l.Fields[i]; f.Kind == Reserved {
+			if f.Size%8 == 0 && f.Bit == 0 && f.Size > 64 {
+				l.SetBytes(data, f, nil)
+			} else {
+				l.Put(data, f, 0)
+			}
+		}
+	}
+}
+
+// Checks that every Field fits in its unit and the structure, and that no two Fields share a bit
+func (l *Layout) Validate() error {
+	used := make([]bool, l.Size*8)
+	for i := range l.Fields {
+		f := &l.Fields[i]
+		if f.Size == 0 {
+			return errors.New(l.Name + ": " + f.Name + " has no bits")
+		}
+
+		// Every bit of the structure the Field covers, as offsets from the start of the structure
+		bits := []uint{}
+		if f.Kind == Bytes || f.Size > 64 {
+			if f.Bit != 0 || f.Size%8 != 0 {
+				return errors.New(l.Name + ": " + f.Name + " must be whole bytes")
+			}
+			for b := uint(0); b < f.Size; b++ {
+				bits = append(bits, f.Offset*8+b)
+			}
+		} else {
+			unit := f.unitSize()
+			if unit > 8 || f.Bit+f.Size > unit*8 {
+				return errors.New(l.Name + ": " + f.Name + " does not fit in a " + strconv.FormatUint(uint64(unit), 10) + " byte unit")
+			}
+			shift := l.shift(f)
+			for b := uint(0); b < f.Size; b++ {
+				pos := shift + b // bit of the unit integer, from the least significant
+				byteIndex := pos / 8
+				if f.Order == BigEndian {
+					byteIndex = unit - 1 - byteIndex
+				}
+				bits = append(bits, (f.Offset+byteIndex)*8+pos%8)
+			}
+		}
+
+		for _, b := range bits {
+			if b >= uint(len(used)) {
+				return errors.New(l.Name + ": " + f.Name + " runs past the end of the structure")
+			}
+			if used[b] {
+				return errors.New(l.Name + ": " + f.Name + " overlaps another field at byte " + strconv.FormatUint(uint64(b/8), 10))
+			}
+			used[b] = true
+		}
+	}
+	return nil
+}
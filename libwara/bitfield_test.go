@@ -0,0 +1,127 @@
+package libwara
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestLayoutsValidate(t *testing.T) {
+	for _, l := range []*Layout{&MiiFormat, &WiiMiiFormat, &SwitchMiiFormat} {
+		if err := l.Validate(); err != nil {
+			t.Errorf("%s: %v", l.Name, err)
+		}
+	}
+
+	for _, l := range []Layout{
+		{Name: "empty field", Size: 1, Fields: []Field{{Name: "a"}}},
+		{Name: "overlap", Size: 1, Fields: []Field{{Name: "a", Size: 4}, {Name: "b", Bit: 3, Size: 2}}},
+		{Name: "past the end", Size: 1, Fields: []Field{{Name: "a", Offset: 1, Size: 1}}},
+		{Name: "too big for its unit", Size: 2, Fields: []Field{{Name: "a", Bit: 4, Size: 8, Unit: 1}}},
+		{Name: "bytes off a byte", Size: 2, Fields: []Field{{Name: "a", Bit: 1, Size: 8, Kind: Bytes}}},
+	} {
+		if err := l.Validate(); err == nil {
+			t.Errorf("no error for a layout with a field %s", l.Name)
+		}
+	}
+}
+
+func TestLayoutGetPut(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		bitOrder BitOrder
+		order    ByteOrder
+		get      uint64
+		put      []byte // data after storing 0xA
+	}{
+		{"LSB first, little endian", LSBFirst, LittleEndian, 0x2, []byte{0x1A, 0x34}},
+		{"MSB first, little endian", MSBFirst, LittleEndian, 0x3, []byte{0x12, 0xA4}},
+		{"LSB first, big endian", LSBFirst, BigEndian, 0x4, []byte{0x12, 0x3A}},
+		{"MSB first, big endian", MSBFirst, BigEndian, 0x1, []byte{0xA2, 0x34}},
+	} {
+		l := Layout{Name: test.name, Size: 2, BitOrder: test.bitOrder}
+		f := &Field{Name: "a", Size: 4, Unit: 2, Order: test.order}
+		data := []byte{0x12, 0x34}
+
+		if got := l.Get(data, f); got != test.get {
+			t.Errorf("%s: got %#x, want %#x", test.name, got, test.get)
+		}
+		l.Put(data, f, 0xA)
+		if !bytes.Equal(data, test.put) {
+			t.Errorf("%s: stored % x, want % x", test.name, data, test.put)
+		}
+		if got := l.Get(data, f); got != 0xA {
+			t.Errorf("%s: read back %#x", test.name, got)
+		}
+		// Bits that do not fit are dropped
+		l.Put(data, f, 0x1F)
+		if got := l.Get(data, f); got != 0xF {
+			t.Errorf("%s: read back %#x after storing too many bits", test.name, got)
+		}
+	}
+}
+
+func TestLayoutGetSigned(t *testing.T) {
+	for _, test := range []struct {
+		bitOrder BitOrder
+		order    ByteOrder
+		data     []byte
+		want     int64
+	}{
+		{LSBFirst, LittleEndian, []byte{0x0F, 0xF0}, -1},
+		{LSBFirst, LittleEndian, []byte{0x07, 0xF0}, 7},
+		{LSBFirst, BigEndian, []byte{0xF0, 0x08}, -8},
+		{MSBFirst, LittleEndian, []byte{0x00, 0x90}, -7},
+		{MSBFirst, BigEndian, []byte{0x70, 0xFF}, 7},
+	} {
+		l := Layout{Size: 2, BitOrder: test.bitOrder}
+		f := &Field{Name: "a", Size: 4, Unit: 2, Order: test.order, Kind: Signed}
+		if got := l.GetSigned(test.data, f); got != test.want {
+			t.Errorf("% x with bit order %d and byte order %d: got %d, want %d", test.data, test.bitOrder, test.order, got, test.want)
+		}
+	}
+}
+
+func TestLayoutSetRange(t *testing.T) {
+	l := Layout{Size: 1}
+	for _, test := range []struct {
+		field Field
+		value uint64
+		ok    bool
+	}{
+		{Field{Size: 8}, 255, true},
+		{Field{Size: 8}, 256, false},
+		{Field{Size: 8, Min: 2, Max: 10}, 1, false},
+		{Field{Size: 8, Min: 2, Max: 10}, 10, true},
+		{Field{Size: 8, Min: 2, Max: 10}, 11, false},
+		{Field{Size: 8, Min: 5}, 4, false},
+		{Field{Size: 8, Min: 5}, 5, true},
+		{Field{Size: 8, Min: 5}, 255, true},
+	} {
+		test.field.Name = "a"
+		err := l.Set(make([]byte, 1), &test.field, test.value)
+		if (err == nil) != test.ok {
+			t.Errorf("%+v: got %v for %d", test.field, err, test.value)
+		}
+	}
+
+	for _, test := range []struct {
+		field Field
+		value int64
+		ok    bool
+	}{
+		{Field{Size: 8}, -128, true},
+		{Field{Size: 8}, 128, false},
+		{Field{Size: 8, Min: -5}, -6, false},
+		{Field{Size: 8, Min: -5}, 0, true},
+		{Field{Size: 8, Min: -5}, 1, false},
+		{Field{Size: 8, Min: 3}, 2, false},
+		{Field{Size: 8, Min: 3}, 127, true},
+	} {
+		test.field.Name = "a"
+		test.field.Kind = Signed
+		err := l.SetSigned(make([]byte, 1), &test.field, test.value)
+		if (err == nil) != test.ok {
+			t.Errorf("%+v: got %v for %d", test.field, err, test.value)
+		}
+	}
+}
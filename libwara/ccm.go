@@ -0,0 +1,108 @@
+package libwara
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"errors"
+)
+
+// AES-CCM as described in RFC 3610, without associated data
+// The standard library has no CCM mode, and Mii QR codes need nothing more than this
+type ccm struct {
+	block    cipher.Block
+	nonceLen int
+	tagLen   int
+}
+
+// Wraps a block cipher with a 16 byte block size
+// nonceLen must be between 7 and 13, tagLen even and between 4 and 16
+func newCCM(block cipher.Block, nonceLen, tagLen int) (*ccm, error) {
+	if block.BlockSize() != 16 {
+		return nil, errors.New("CCM needs a 16 byte block cipher")
+	}
+	if nonceLen < 7 || nonceLen > 13 || tagLen < 4 || tagLen > 16 || tagLen%2 != 0 {
+		return nil, errors.New("invalid CCM nonce or tag size")
+	}
+	return &ccm{block: block, nonceLen: nonceLen, tagLen: tagLen}, nil
+}
+
+// Sets dst to a xor b, for as many bytes as the shortest of them has
+func xorBytes(dst, a, b []byte) {
+	for i := 0; i < len(dst) && i < len(a) && i < len(b); i++ {
+		dst[i] = a[i] ^ b[i]
+	}
+}
+
+// Returns counter block i, or the first MAC block B0 when flags holds the MAC flags
+func (c *ccm) counterBlock(flags byte, nonce []byte, i int) []byte {
+	b := make([]byte, 16)
+	b[0] = flags | byte(15-c.nonceLen-1)
+	copy(b[1:], nonce)
+	for j := 15; j > c.nonceLen; j-- {
+		b[j] = byte(i)
+		i >>= 8
+	}
+	return b
+}
+
+// Returns the CBC-MAC of a message
+func (c *ccm) mac(nonce, plaintext []byte) []byte {
+	// No associated data, so only the tag size goes in the flags
+	tag := c.counterBlock(byte((c.tagLen-2)/2)<<3, nonce, len(plaintext))
+	c.block.Encrypt(tag, tag)
+
+	for start := 0; start < len(plaintext); start += 16 {
+		end := start + 16
+		if end > len(plaintext) {
+			end = len(plaintext)
+		}
+		xorBytes(tag, tag, plaintext[start:end])
+		c.block.Encrypt(tag, tag)
+	}
+	return tag[:c.tagLen]
+}
+
+// Encrypts or decrypts data in counter mode, starting from counter 1
+func (c *ccm) ctr(nonce, dst, src []byte) {
+	stream := make([]byte, 16)
+	for start, i := 0, 1; start < len(src); start, i = start+16, i+1 {
+		c.block.Encrypt(stream, c.counterBlock(0, nonce, i))
+		end := start + 16
+		if end > len(src) {
+			end = len(src)
+		}
+		xorBytes(dst[start:end], src[start:end], stream)
+	}
+}
+
+// Returns the tag encrypted with counter 0
+func (c *ccm) encryptTag(nonce, tag []byte) []byte {
+	s0 := make([]byte, 16)
+	c.block.Encrypt(s0, c.counterBlock(0, nonce, 0))
+	ret := make([]byte, c.tagLen)
+	xorBytes(ret, tag, s0)
+	return ret
+}
+
+// Encrypts a message and appends the tag
+func (c *ccm) seal(nonce, plaintext []byte) []byte {
+	ret := make([]byte, len(plaintext), len(plaintext)+c.tagLen)
+	c.ctr(nonce, ret, plaintext)
+	return append(ret, c.encryptTag(nonce, c.mac(nonce, plaintext))...)
+}
+
+// Decrypts a message followed by its tag, failing if the tag does not match
+func (c *ccm) open(nonce, ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < c.tagLen {
+		return nil, errors.New("CCM message shorter than its tag")
+	}
+	data := ciphertext[:len(ciphertext)-c.tagLen]
+	plaintext := make([]byte, len(data))
+	c.ctr(nonce, plaintext, data)
+
+	tag := c.encryptTag(nonce, c.mac(nonce, plaintext))
+	if subtle.ConstantTimeCompare(tag, ciphertext[len(data):]) != 1 {
+		return nil, errors.New("CCM tag does not match")
+	}
+	return plaintext, nil
+}
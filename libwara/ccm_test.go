@@ -0,0 +1,82 @@
+package libwara
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"testing"
+)
+
+// Decodes hex in a test vector
+func unhex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// RFC 3610 has no vectors without associated data. The encrypted part of packet vector #1 does not depend on its 8 byte
+// header, so it is checked against the RFC. The tags cover no header, so they were computed with the CCM of pion/dtls
+func TestCCMVectors(t *testing.T) {
+	key := unhex(t, "c0c1c2c3c4c5c6c7c8c9cacbcccdcecf")
+	for _, v := range []struct {
+		name      string
+		nonce     string
+		plaintext string
+		tagLen    int
+		sealed    string
+	}{
+		{
+			"RFC 3610 packet vector #1 without its header",
+			"00000003020100a0a1a2a3a4a5",
+			"08090a0b0c0d0e0f101112131415161718191a1b1c1d1e",
+			8,
+			"588c979a61c663d2f066d0c2c0f989806d5f6b61dac384" + "7c2051a7ae200bcf",
+		},
+		{
+			"Mii QR code sizes",
+			"000102030405060708090a0b",
+			"000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f20",
+			16,
+			"995aabd7f46eb103c41dc53e7ca9d4e30f4f8478f098fa98859d8de01d0637b605" + "8ee6ae21267d9ad4f2f7ea2561ac6ef3",
+		},
+	} {
+		t.Run(v.name, func(t *testing.T) {
+			block, err := aes.NewCipher(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			nonce, plaintext, want := unhex(t, v.nonce), unhex(t, v.plaintext), unhex(t, v.sealed)
+			c, err := newCCM(block, len(nonce), v.tagLen)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			sealed := c.seal(nonce, plaintext)
+			if !bytes.Equal(sealed, want) {
+				t.Errorf("got %x, want %x", sealed, want)
+			}
+			opened, err := c.open(nonce, want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(opened, plaintext) {
+				t.Errorf("opened %x, want %x", opened, plaintext)
+			}
+
+			// Changing any byte, of the message or of the tag, must fail
+			for _, i := range []int{0, len(plaintext) - 1, len(want) - 1} {
+				tampered := append([]byte{}, want...)
+				tampered[i] ^= 1
+				if _, err := c.open(nonce, tampered); err == nil {
+					t.Errorf("opened a message with byte %d changed", i)
+				}
+			}
+			if _, err := c.open(nonce, want[:v.tagLen-1]); err == nil {
+				t.Error("opened a message shorter than its tag")
+			}
+		})
+	}
+}
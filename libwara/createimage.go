@@ -69,17 +69,12 @@ func convertTga(w io.Writer, img image.Image) error {
 		return err
 	}
 
-	startX := 0
-	startY := img.Bounds().Dy() - 1
-	strideX := 1
-	strideY := -1
-
-	for y := startY; 0 <= y && y < img.Bounds().Dy(); y += strideY {
-		for x := startX; 0 <= x && x < img.Bounds().Dx(); x += strideX {
-			color := img.At(x, y)
-
-			r, g, b, a := color.RGBA()
-			colorBytes := []byte{byte(b), byte(g), byte(r), byte(a)}
+	// TGA stores rows bottom to top, with straight rather than premultiplied alpha
+	bounds := img.Bounds()
+	for y := bounds.Max.Y - 1; y >= bounds.Min.Y; y-- {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			colorBytes := []byte{c.B, c.G, c.R, c.A}
 
 			err := binary.Write(w, binary.BigEndian, colorBytes)
 			if err != nil {
@@ -99,15 +94,12 @@ func convertTga(w io.Writer, img image.Image) error {
 // Takes a string (input image path) and two int's (output image x and y size), and returns a pointer to a byte buffer and an error
 // returns a nil error if no errors occurred
 func makeImage(inPath string, xSize, ySize int) (*bytes.Buffer, error) {
-	// Open image file
-	imageFileIn, err := os.Open(inPath)
+	// Read image file
+	fileData, err := os.ReadFile(inPath)
 	if err != nil {
 		return nil, err
 	}
-	defer imageFileIn.Close()
-
-	// Read image file
-	imgData, _, err := image.Decode(imageFileIn)
+	imgData, err := ReadImage(fileData)
 	if err != nil {
 		return nil, err
 	}
@@ -144,6 +136,15 @@ func convertToNintendo(fileData bytes.Buffer) (string, error) {
 	return encodedData, nil
 }
 
+// Encodes an image that is already the right size to the WaraWaraPlaza format
+func encodeImage(img image.Image) (string, error) {
+	var tga bytes.Buffer
+	if err := convertTga(&tga, img); err != nil {
+		return "", err
+	}
+	return convertToNintendo(tga)
+}
+
 // Encodes an image to the WaraWaraPlaza format
 // Takes an image path (String), the type of image (ImageSize), and the dimensions of an image if the image type is CustomImage
 // Returns a String of encoded image and an Error
@@ -0,0 +1,115 @@
+package libwara
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"io"
+	"strconv"
+)
+
+// Largest width or height DecodeImage accepts. Icons are 128x128 and paintings 300x100
+const MAX_IMAGE_DIMENSION = 1024
+
+// Most pixels ReadImage decodes. Far more than any icon or painting needs, but small enough that
+// an image claiming huge dimensions cannot exhaust memory
+const MAX_SOURCE_PIXELS = 40_000_000
+
+// Size of the TGA header in bytes
+const tgaHeaderSize = 18
+
+// Decodes an image in the WaraWaraPlaza format, as made by CreateImage or found in a scraped 1stNUP
+// Only uncompressed 24 and 32 bit TGA images are supported
+func DecodeImage(encoded string) (image.Image, error) {
+	compressed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := zlib.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	// Never inflate more than the largest image allowed, however much the data claims
+	maxSize := int64(tgaHeaderSize + 255 + MAX_IMAGE_DIMENSION*MAX_IMAGE_DIMENSION*4 + len(Footer))
+	tga, err := io.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(tga)) > maxSize {
+		return nil, errors.New("image data is larger than " + strconv.FormatInt(maxSize, 10) + " bytes")
+	}
+
+	return decodeTga(tga)
+}
+
+// Decodes a PNG or JPEG image from untrusted data, such as a downloaded Reddit image
+// The dimensions are checked before any pixels are decoded
+func ReadImage(data []byte) (image.Image, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > MAX_SOURCE_PIXELS {
+		return nil, errors.New("image is " + strconv.Itoa(config.Width) + "x" + strconv.Itoa(config.Height) + ", more than " + strconv.Itoa(MAX_SOURCE_PIXELS) + " pixels")
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
+}
+
+// Decodes an uncompressed true color TGA image
+func decodeTga(data []byte) (image.Image, error) {
+	if len(data) < tgaHeaderSize {
+		return nil, errors.New("TGA data too short for a header")
+	}
+	header := Header{}
+	if err := binary.Read(bytes.NewReader(data), binary.LittleEndian, &header); err != nil {
+		return nil, err
+	}
+
+	if header.ImageType != 0x02 || header.ColorMapType != 0x00 {
+		return nil, errors.New("only uncompressed true color TGA images are supported, got image type " + strconv.Itoa(int(header.ImageType)))
+	}
+	if header.PixelDepth != 24 && header.PixelDepth != 32 {
+		return nil, errors.New("only 24 and 32 bit TGA images are supported, got " + strconv.Itoa(int(header.PixelDepth)) + " bits")
+	}
+	width, height := int(header.ImageWidth), int(header.ImageHeight)
+	if width > MAX_IMAGE_DIMENSION || height > MAX_IMAGE_DIMENSION {
+		return nil, errors.New("TGA image is " + strconv.Itoa(width) + "x" + strconv.Itoa(height) + ", larger than " + strconv.Itoa(MAX_IMAGE_DIMENSION))
+	}
+
+	pixelSize := int(header.PixelDepth) / 8
+	start := tgaHeaderSize + int(header.IdLength)
+	end := start + width*height*pixelSize
+	if len(data) < end {
+		return nil, errors.New("TGA data too short for a " + strconv.Itoa(width) + "x" + strconv.Itoa(height) + " image")
+	}
+	pixels := data[start:end]
+
+	// Rows are stored bottom to top unless bit 5 of the descriptor is set
+	topDown := header.ImageDescriptor&0x20 != 0
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for row := 0; row < height; row++ {
+		y := height - 1 - row
+		if topDown {
+			y = row
+		}
+		for x := 0; x < width; x++ {
+			p := pixels[(row*width+x)*pixelSize:]
+			c := color.NRGBA{B: p[0], G: p[1], R: p[2], A: 0xFF}
+			if pixelSize == 4 {
+				c.A = p[3]
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+
+	return img, nil
+}
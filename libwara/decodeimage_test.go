@@ -0,0 +1,195 @@
+package libwara
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"testing"
+)
+
+// Returns a TGA header followed by pixel bytes, as decodeTga reads it
+func tgaData(width, height uint16, depth, descriptor byte, pixels int) []byte {
+	buf := bytes.Buffer{}
+	header := Header{
+		ImageType:       0x02,
+		ImageWidth:      width,
+		ImageHeight:     height,
+		PixelDepth:      depth,
+		ImageDescriptor: descriptor,
+	}
+	binary.Write(&buf, binary.LittleEndian, &header)
+	buf.Write(make([]byte, pixels))
+	return buf.Bytes()
+}
+
+// Returns TGA data as DecodeImage takes it
+func encodeTgaData(tga []byte) string {
+	buf := bytes.Buffer{}
+	w := zlib.NewWriter(&buf)
+	w.Write(tga)
+	w.Close()
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+// Returns a PNG claiming to be width by height, with no pixel data after the header
+func pngHeader(width, height uint32) []byte {
+	img := image.NewGray(image.Rect(0, 0, 1, 1))
+	buf := bytes.Buffer{}
+	png.Encode(&buf, img)
+	data := buf.Bytes()
+	// IHDR data starts after the 8 byte signature, 4 byte length and 4 byte type
+	binary.BigEndian.PutUint32(data[16:], width)
+	binary.BigEndian.PutUint32(data[20:], height)
+	return data[:33]
+}
+
+// Returns the TGA data of the default icon
+func defaultIconTga(t testing.TB) []byte {
+	t.Helper()
+	compressed, err := base64.StdEncoding.DecodeString(defaultIcon)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := zlib.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// TGA inputs covering truncated and oversized headers and the dimension limit
+func tgaSeeds() [][]byte {
+	valid := tgaData(2, 2, 32, 0, 2*2*4)
+	return [][]byte{
+		{},
+		valid[:1],
+		valid[:tgaHeaderSize-1],
+		valid[:tgaHeaderSize],
+		valid,
+		valid[:len(valid)-1],
+		tgaData(2, 2, 24, 0x20, 2*2*3),
+		tgaData(2, 2, 16, 0, 2*2*2),
+		tgaData(0, 0, 32, 0, 0),
+		tgaData(MAX_IMAGE_DIMENSION, 1, 32, 0, MAX_IMAGE_DIMENSION*4),
+		tgaData(MAX_IMAGE_DIMENSION+1, 1, 32, 0, 0),
+		tgaData(1, MAX_IMAGE_DIMENSION+1, 24, 0, 0),
+		tgaData(0xFFFF, 0xFFFF, 32, 0, 16),
+		append([]byte{0xFF}, valid[1:]...), // an ID field longer than the data
+	}
+}
+
+func TestDecodeTgaLimits(t *testing.T) {
+	if _, err := decodeTga(tgaData(MAX_IMAGE_DIMENSION, 1, 32, 0, MAX_IMAGE_DIMENSION*4)); err != nil {
+		t.Errorf("largest allowed image: %v", err)
+	}
+	for _, data := range [][]byte{
+		tgaData(MAX_IMAGE_DIMENSION+1, 1, 32, 0, (MAX_IMAGE_DIMENSION+1)*4),
+		tgaData(2, 2, 32, 0, 2*2*4-1),
+		tgaData(2, 2, 32, 0, 2*2*4)[:tgaHeaderSize-1],
+	} {
+		if _, err := decodeTga(data); err == nil {
+			t.Errorf("accepted %d bytes", len(data))
+		}
+	}
+}
+
+func TestDecodeImageRoundTrip(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	img.SetNRGBA(2, 1, color.NRGBA{R: 1, G: 2, B: 3, A: 4})
+	encoded, err := encodeImage(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := DecodeImage(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Bounds() != img.Bounds() {
+		t.Fatalf("got bounds %v", decoded.Bounds())
+	}
+	if got := color.NRGBAModel.Convert(decoded.At(2, 1)); got != img.At(2, 1) {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestReadImageLimits(t *testing.T) {
+	if _, err := ReadImage(pngHeader(10000, 10000)); err == nil {
+		t.Error("accepted an image over MAX_SOURCE_PIXELS")
+	}
+	if _, err := ReadImage(pngHeader(1<<31-1, 1<<31-1)); err == nil {
+		t.Error("accepted an image whose pixel count overflows")
+	}
+}
+
+func FuzzDecodeTga(f *testing.F) {
+	for _, seed := range tgaSeeds() {
+		f.Add(seed)
+	}
+	icon := defaultIconTga(f)
+	f.Add(icon)
+	f.Add(icon[:len(icon)/2])
+	f.Fuzz(func(t *testing.T, data []byte) {
+		img, err := decodeTga(data)
+		if err != nil {
+			return
+		}
+		if b := img.Bounds(); b.Dx() > MAX_IMAGE_DIMENSION || b.Dy() > MAX_IMAGE_DIMENSION {
+			t.Fatalf("decoded a %dx%d image", b.Dx(), b.Dy())
+		}
+	})
+}
+
+func FuzzDecodeImage(f *testing.F) {
+	for _, seed := range tgaSeeds() {
+		f.Add(encodeTgaData(seed))
+	}
+	f.Add(defaultIcon)
+	f.Add(defaultIcon[:len(defaultIcon)/2])
+	f.Add("")
+	f.Add("not base64!")
+	f.Add(base64.StdEncoding.EncodeToString([]byte("not zlib")))
+	// Inflates to more than the largest image allowed
+	f.Add(encodeTgaData(tgaData(MAX_IMAGE_DIMENSION, MAX_IMAGE_DIMENSION, 32, 0, MAX_IMAGE_DIMENSION*MAX_IMAGE_DIMENSION*4+1024)))
+
+	f.Fuzz(func(t *testing.T, encoded string) {
+		img, err := DecodeImage(encoded)
+		if err != nil {
+			return
+		}
+		if b := img.Bounds(); b.Dx() > MAX_IMAGE_DIMENSION || b.Dy() > MAX_IMAGE_DIMENSION {
+			t.Fatalf("decoded a %dx%d image", b.Dx(), b.Dy())
+		}
+	})
+}
+
+func FuzzReadImage(f *testing.F) {
+	small := bytes.Buffer{}
+	png.Encode(&small, image.NewGray(image.Rect(0, 0, 2, 2)))
+	f.Add(small.Bytes())
+	f.Add(small.Bytes()[:20])
+	f.Add(pngHeader(10000, 10000))
+	f.Add(pngHeader(MAX_SOURCE_PIXELS, 1))
+	f.Add(pngHeader(MAX_SOURCE_PIXELS+1, 1))
+	f.Add(pngHeader(1<<31-1, 1<<31-1))
+	f.Add([]byte{})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		img, err := ReadImage(data)
+		if err != nil {
+			return
+		}
+		if b := img.Bounds(); b.Dx()*b.Dy() > MAX_SOURCE_PIXELS {
+			t.Fatalf("decoded a %dx%d image", b.Dx(), b.Dy())
+		}
+	})
+}
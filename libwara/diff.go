@@ -0,0 +1,176 @@
+package libwara
+
+import (
+	"strconv"
+)
+
+// How a Topic or Post differs between two Nups
+type ChangeKind int
+
+const (
+	Added ChangeKind = iota
+	Removed
+	Changed
+)
+
+// Returns "+", "-" or "~"
+func (k ChangeKind) String() string {
+	switch k {
+	case Added:
+		return "+"
+	case Removed:
+		return "-"
+	}
+	return "~"
+}
+
+// A Topic that was added, removed or changed
+type TopicDiff struct {
+	Kind   ChangeKind
+	Name   string
+	Fields []string   // Fields of the Topic itself that changed, by their XML names
+	Posts  []PostDiff // Posts that were added, removed or changed
+}
+
+// A Post that was added, removed or changed
+// Posts are matched by their screen name and creation time
+type PostDiff struct {
+	Kind   ChangeKind
+	Old    *Post    // nil for added Posts
+	New    *Post    // nil for removed Posts
+	Fields []string // Fields that changed, by their XML names
+}
+
+// A field compared by DiffNups
+type diffField[T any] struct {
+	name string
+	get  func(*T) string
+}
+
+// Topic fields compared by DiffNups. modified_at is left out, it changes on every build
+var topicDiffFields = []diffField[Topic]{
+	{"icon", func(t *Topic) string { return t.Icon }},
+	{"title_id", func(t *Topic) string { return strconv.FormatUint(uint64(t.TitleId), 10) }},
+	{"community_id", func(t *Topic) string { return strconv.FormatUint(uint64(t.CommunityId), 10) }},
+	{"is_recommended", func(t *Topic) string { return strconv.Itoa(t.IsRecommended) }},
+	{"participant_count", func(t *Topic) string { return strconv.FormatUint(uint64(t.ParticipantCount), 10) }},
+	{"empathy_count", func(t *Topic) string { return strconv.Itoa(t.EmpathyCount) }},
+	{"has_shop_page", func(t *Topic) string { return strconv.Itoa(t.HasShopPage) }},
+	{"position", func(t *Topic) string { return strconv.Itoa(t.Position) }},
+}
+
+// Post fields compared by DiffNups
+var postDiffFields = []diffField[Post]{
+	{"body", func(p *Post) string { return p.Body }},
+	{"country_id", func(p *Post) string { return strconv.Itoa(int(p.CountryId)) }},
+	{"feeling_id", func(p *Post) string { return strconv.Itoa(int(p.FeelingId)) }},
+	{"is_spoiler", func(p *Post) string { return strconv.Itoa(p.IsSpoiler) }},
+	{"empathy_count", func(p *Post) string { return p.EmpathyCount }},
+	{"language_id", func(p *Post) string { return strconv.Itoa(int(p.LanguageId)) }},
+	{"mii", func(p *Post) string { return p.MiiData }},
+	{"number", func(p *Post) string { return strconv.Itoa(p.Number) }},
+	{"painting", func(p *Post) string { return p.PaintingFormat + p.PaintingContent }},
+	{"platform_id", func(p *Post) string { return strconv.Itoa(int(p.PlatformId)) }},
+	{"region_id", func(p *Post) string { return strconv.Itoa(int(p.RegionId)) }},
+	{"reply_count", func(p *Post) string { return strconv.Itoa(p.ReplyCount) }},
+}
+
+// Returns the names of the fields that differ between a and b
+func changedFields[T any](fields []diffField[T], a, b *T) []string {
+	ret := []string{}
+	for _, f := range fields {
+		if f.get(a) != f.get(b) {
+			ret = append(ret, f.name)
+		}
+	}
+	return ret
+}
+
+// Reports the Topics and Posts that were added, removed or changed going from Nup a to Nup b
+// Topics are matched by name, removed Topics come first, then the Topics of b in order
+// A nil Nup is compared as an empty one
+func DiffNups(a, b *Nup) []TopicDiff {
+	if a == nil {
+		a = InitNup()
+	}
+	if b == nil {
+		b = InitNup()
+	}
+	oldTopics := a.snapshot()
+	newTopics := b.snapshot()
+	ret := []TopicDiff{}
+
+	newByName := map[string]*Topic{}
+	for i := range newTopics {
+		newByName[newTopics[i].Name] = &newTopics[i]
+	}
+	oldByName := map[string]*Topic{}
+	for i := range oldTopics {
+		t := &oldTopics[i]
+		oldByName[t.Name] = t
+		if newByName[t.Name] == nil {
+			ret = append(ret, TopicDiff{Kind: Removed, Name: t.Name, Posts: diffPosts(t, &Topic{})})
+		}
+	}
+
+	for i := range newTopics {
+		t := &newTopics[i]
+		old := oldByName[t.Name]
+		if old == nil {
+			ret = append(ret, TopicDiff{Kind: Added, Name: t.Name, Posts: diffPosts(&Topic{}, t)})
+			continue
+		}
+
+		d := TopicDiff{Kind: Changed, Name: t.Name, Fields: changedFields(topicDiffFields, old, t), Posts: diffPosts(old, t)}
+		if len(d.Fields) > 0 || len(d.Posts) > 0 {
+			ret = append(ret, d)
+		}
+	}
+
+	return ret
+}
+
+// Identifies a Post across Nups
+type postKey struct {
+	author    string
+	createdAt string
+}
+
+// Reports the Posts that were added, removed or changed going from Topic a to Topic b
+// Posts with the same author and time are matched in the order they appear
+func diffPosts(a, b *Topic) []PostDiff {
+	ret := []PostDiff{}
+
+	oldPosts := map[postKey][]*Post{}
+	for _, p := range a.Posts() {
+		k := postKey{p.ScreenName, p.CreatedAt}
+		oldPosts[k] = append(oldPosts[k], p)
+	}
+
+	added := []PostDiff{}
+	for _, p := range b.Posts() {
+		k := postKey{p.ScreenName, p.CreatedAt}
+		if len(oldPosts[k]) == 0 {
+			added = append(added, PostDiff{Kind: Added, New: p})
+			continue
+		}
+		old := oldPosts[k][0]
+		oldPosts[k] = oldPosts[k][1:]
+		if fields := changedFields(postDiffFields, old, p); len(fields) > 0 {
+			ret = append(ret, PostDiff{Kind: Changed, Old: old, New: p, Fields: fields})
+		}
+	}
+
+	// Removed Posts are reported in the order of Topic a
+	removed := []PostDiff{}
+	for _, p := range a.Posts() {
+		k := postKey{p.ScreenName, p.CreatedAt}
+		for _, left := range oldPosts[k] {
+			if left == p {
+				removed = append(removed, PostDiff{Kind: Removed, Old: p})
+			}
+		}
+	}
+
+	return append(append(removed, added...), ret...)
+}
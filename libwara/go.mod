@@ -2,4 +2,13 @@ module cornchip.com/libwara/v2
 
 go 1.19
 
-require golang.org/x/image v0.3.0
+require (
+	github.com/makiuchi-d/gozxing v0.1.1
+	github.com/skip2/go-qrcode v0.0.0-20200617195104-da1b6568686e
+	golang.org/x/image v0.3.0
+)
+
+require (
+	golang.org/x/text v0.6.0 // indirect
+	golang.org/x/xerrors v0.0.0-20200804184101-5ec99f83aff1 // indirect
+)
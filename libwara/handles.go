@@ -0,0 +1,389 @@
+package libwara
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// Identifies a Topic of a Nup. Unlike a pointer, a handle stays valid while other Topics are added or removed
+type TopicHandle uint64
+
+// Identifies a Post of a Nup. Unlike a pointer, a handle stays valid while other Posts are added, removed or moved
+type PostHandle uint64
+
+// Returned when a handle does not belong to any Topic or Post of the Nup, for example after it was removed
+var ErrNotFound = errors.New("not found")
+
+// Returned when a Topic would get the name or title ID of another Topic of the Nup
+var ErrTopicExists = errors.New("Topic already exists")
+
+// Returns a handle no other Topic or Post of the Nup uses. The Nup must be locked
+func (n *Nup) newHandle() uint64 {
+	n.lastHandle++
+	return n.lastHandle
+}
+
+// Returns the Topic of a handle. The Nup must be locked
+func (n *Nup) topicByHandle(h TopicHandle) (*Topic, error) {
+	for i := range n.Topics {
+		if n.Topics[i].handle == h {
+			return &n.Topics[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: topic %s", ErrNotFound, strconv.FormatUint(uint64(h), 10))
+}
+
+// Returns the Post of a handle and the Topic it is in. The Nup must be locked
+func (n *Nup) postByHandle(h PostHandle) (*Topic, *Post, error) {
+	t, person, index, err := n.findPost(h)
+	if err != nil {
+		return nil, nil, err
+	}
+	return t, &t.People[person].Posts[index], nil
+}
+
+// Returns the Topic of a Post handle and where the Post is in it. The Nup must be locked
+func (n *Nup) findPost(h PostHandle) (t *Topic, person int, index int, err error) {
+	for i := range n.Topics {
+		t := &n.Topics[i]
+		for j := range t.People {
+			for k := range t.People[j].Posts {
+				if t.People[j].Posts[k].handle == h {
+					return t, j, k, nil
+				}
+			}
+		}
+	}
+	return nil, 0, 0, fmt.Errorf("%w: post %s", ErrNotFound, strconv.FormatUint(uint64(h), 10))
+}
+
+// Returns a copy of a Topic that shares nothing with the Nup
+func (t *Topic) clone() Topic {
+	ret := *t
+	ret.People = make([]Person, len(t.People))
+	for i := range t.People {
+		ret.People[i] = t.People[i]
+		ret.People[i].Posts = append([]Post{}, t.People[i].Posts...)
+	}
+	return ret
+}
+
+// Returns the handle of the Topic
+func (t *Topic) Handle() TopicHandle {
+	return t.handle
+}
+
+// Returns the handle of the Post
+func (p *Post) Handle() PostHandle {
+	return p.handle
+}
+
+// Adds an empty Topic with a specified name to the Nup and returns its handle
+// The Topic is given the first default title ID not already used by another Topic
+func (n *Nup) NewTopic(name string) (TopicHandle, error) {
+	if n == nil {
+		return 0, errors.New("no Nup provided")
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if err := n.currentLimits().checkAddTopic(n); err != nil {
+		return 0, err
+	}
+
+	for _, id := range defaultTitleIds {
+		if !n.hasTitleId(id) {
+			return n.addTopic(name, id, defaultIcon)
+		}
+	}
+
+	return 0, fmt.Errorf("%w: no unused default title IDs left", ErrTooManyTopics)
+}
+
+// Adds an empty Topic for a game in the title catalog and returns its handle. The Topic is named after the game
+// If a region is provided, the release from that region is used
+func (n *Nup) NewTopicForTitle(gameName string, region ...Region) (TopicHandle, error) {
+	if n == nil {
+		return 0, errors.New("no Nup provided")
+	}
+
+	title, err := LookupTitle(gameName, region...)
+	if err != nil {
+		return 0, err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.addTopic(title.Name, title.TitleId, title.TopicIcon())
+}
+
+// Returns the handle of the Topic with a given name
+func (n *Nup) TopicHandle(name string) (TopicHandle, error) {
+	if n == nil {
+		return 0, errors.New("no Nup provided")
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	t, err := n.getTopic(name)
+	if err != nil {
+		return 0, err
+	}
+	return t.handle, nil
+}
+
+// Returns a copy of a Topic, including its Posts
+// Changing the copy does not change the Nup, see EditTopic
+func (n *Nup) Topic(h TopicHandle) (Topic, error) {
+	if n == nil {
+		return Topic{}, errors.New("no Nup provided")
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	t, err := n.topicByHandle(h)
+	if err != nil {
+		return Topic{}, err
+	}
+	return t.clone(), nil
+}
+
+// Calls fn with a Topic while the Nup is locked
+// fn may change the fields of the Topic, but must not add or remove People or Posts, keep the pointer, or call methods of the Nup
+// If fn returns an error, its changes are undone and the error is returned
+// If fn gives the Topic the name or title ID of another Topic, its changes are undone and ErrTopicExists is returned
+func (n *Nup) EditTopic(h TopicHandle, fn func(t *Topic) error) error {
+	if n == nil {
+		return errors.New("no Nup provided")
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	t, err := n.topicByHandle(h)
+	if err != nil {
+		return err
+	}
+	before := t.clone()
+	if err := fn(t); err != nil {
+		*t = before
+		return err
+	}
+	t.handle = h
+
+	for i := range n.Topics {
+		other := &n.Topics[i]
+		if other == t {
+			continue
+		}
+		if other.Name == t.Name {
+			err = fmt.Errorf("%w: name %s", ErrTopicExists, t.Name)
+		} else if other.TitleId == t.TitleId {
+			err = fmt.Errorf("%w: title ID %s", ErrTopicExists, strconv.FormatUint(uint64(t.TitleId), 10))
+		}
+		if err != nil {
+			*t = before
+			return err
+		}
+	}
+	return nil
+}
+
+// Removes a Topic and its Posts from the Nup
+func (n *Nup) DeleteTopic(h TopicHandle) error {
+	if n == nil {
+		return errors.New("no Nup provided")
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for i := range n.Topics {
+		if n.Topics[i].handle == h {
+			n.totalPosts -= n.Topics[i].PostCount()
+			n.Topics = append(n.Topics[:i], n.Topics[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: topic %s", ErrNotFound, strconv.FormatUint(uint64(h), 10))
+}
+
+// Returns the handle of every Topic, in order
+func (n *Nup) TopicHandles() []TopicHandle {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	ret := make([]TopicHandle, len(n.Topics))
+	for i := range n.Topics {
+		ret[i] = n.Topics[i].handle
+	}
+	return ret
+}
+
+// Calls fn for every Topic in order while the Nup is locked, until fn returns false
+// fn must not keep the pointer or call methods of the Nup
+func (n *Nup) EachTopic(fn func(h TopicHandle, t *Topic) bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for i := range n.Topics {
+		if !fn(n.Topics[i].handle, &n.Topics[i]) {
+			return
+		}
+	}
+}
+
+// Adds a post to a Topic and returns its handle, see AddPost
+func (n *Nup) NewPost(topic TopicHandle, opts ...PostOption) (PostHandle, error) {
+	if n == nil {
+		return 0, errors.New("no Nup provided")
+	}
+	post, err := buildPost(opts)
+	if err != nil {
+		return 0, err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	t, err := n.topicByHandle(topic)
+	if err != nil {
+		return 0, err
+	}
+	added, err := n.addPost(t, post)
+	if err != nil {
+		return 0, err
+	}
+	return added.handle, nil
+}
+
+// Returns a copy of a Post
+// Changing the copy does not change the Nup, see SetPost and EditPost
+func (n *Nup) Post(h PostHandle) (Post, error) {
+	if n == nil {
+		return Post{}, errors.New("no Nup provided")
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	_, p, err := n.postByHandle(h)
+	if err != nil {
+		return Post{}, err
+	}
+	return *p, nil
+}
+
+// Replaces a Post with a changed copy, as returned by Post
+// A Post with a different ScreenName moves to the Person of its new author, see EditPost
+func (n *Nup) SetPost(h PostHandle, post Post) error {
+	return n.EditPost(h, func(p *Post) error {
+		*p = post
+		p.handle = h
+		return nil
+	})
+}
+
+// Calls fn with a Post while the Nup is locked
+// fn may change the fields of the Post, but must not keep the pointer or call methods of the Nup
+// If fn returns an error, its changes are undone and the error is returned
+// If fn changes the ScreenName, the Post moves to the end of the Posts of its new author. If that breaks the limits
+// of the Nup or the ScreenName is blank, the changes of fn are undone and the error is returned
+func (n *Nup) EditPost(h PostHandle, fn func(p *Post) error) error {
+	if n == nil {
+		return errors.New("no Nup provided")
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	t, person, index, err := n.findPost(h)
+	if err != nil {
+		return err
+	}
+	p := &t.People[person].Posts[index]
+	before := *p
+	if err := fn(p); err != nil {
+		*p = before
+		return err
+	}
+	p.handle = h
+
+	if p.ScreenName == before.ScreenName {
+		return nil
+	}
+	if p.ScreenName == "" {
+		*p = before
+		return fmt.Errorf("%w: empty author", ErrInvalidPost)
+	}
+	if err := n.regroupPost(t, person, index); err != nil {
+		*p = before
+		return err
+	}
+	return nil
+}
+
+// Moves a Post whose ScreenName changed to the Person of its new author. The Nup must be locked
+func (n *Nup) regroupPost(t *Topic, person int, index int) error {
+	post := t.People[person].Posts[index]
+	author := post.ScreenName
+
+	// The old Person only frees its place if this was its last Post
+	limit := n.currentLimits().MaxPeoplePerTopic
+	if t.GetPerson(author) == nil && len(t.People[person].Posts) > 1 && uint(len(t.People)) >= limit {
+		return fmt.Errorf("%w %s: limit is %d", ErrTooManyPeople, t.Name, limit)
+	}
+
+	n.removePost(t, person, index)
+	p := t.GetPerson(author)
+	if p == nil {
+		t.People = append(t.People, Person{Author: author, Posts: []Post{}})
+		p = &t.People[len(t.People)-1]
+	}
+	p.Posts = append(p.Posts, post)
+	t.ParticipantCount = uint(len(t.People))
+	n.totalPosts++
+	return nil
+}
+
+// Removes a Post from its Topic
+func (n *Nup) DeletePost(h PostHandle) error {
+	if n == nil {
+		return errors.New("no Nup provided")
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	t, person, index, err := n.findPost(h)
+	if err != nil {
+		return err
+	}
+	n.removePost(t, person, index)
+	return nil
+}
+
+// Returns the handle of every Post in a Topic, person by person
+func (n *Nup) PostHandles(topic TopicHandle) ([]PostHandle, error) {
+	ret := []PostHandle{}
+	err := n.EachPost(topic, func(h PostHandle, p *Post) bool {
+		ret = append(ret, h)
+		return true
+	})
+	return ret, err
+}
+
+// Calls fn for every Post of a Topic, person by person, while the Nup is locked, until fn returns false
+// fn must not keep the pointer or call methods of the Nup
+func (n *Nup) EachPost(topic TopicHandle, fn func(h PostHandle, p *Post) bool) error {
+	if n == nil {
+		return errors.New("no Nup provided")
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	t, err := n.topicByHandle(topic)
+	if err != nil {
+		return err
+	}
+	for _, p := range t.Posts() {
+		if !fn(p.handle, p) {
+			break
+		}
+	}
+	return nil
+}
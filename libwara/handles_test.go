@@ -0,0 +1,150 @@
+package libwara
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEditPostRegroups(t *testing.T) {
+	n, topic, posts := testNup(t, "a", "a", "b")
+
+	err := n.EditPost(posts[0], func(p *Post) error {
+		p.ScreenName = "b"
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authors := authorsOf(t, n, topic); len(authors) != 3 || authors[0] != "a" || authors[1] != "b" || authors[2] != "b" {
+		t.Errorf("got %v", authors)
+	}
+	got, _ := n.Topic(topic)
+	if len(got.People) != 2 || len(got.People[1].Posts) != 2 || got.People[1].Posts[1].handle != posts[0] {
+		t.Errorf("the Post did not move to the end of the Posts of b: %+v", got.People)
+	}
+
+	// Moving the last Post of a Person removes the Person
+	post, _ := n.Post(posts[1])
+	post.ScreenName = "c"
+	if err := n.SetPost(posts[1], post); err != nil {
+		t.Fatal(err)
+	}
+	got, _ = n.Topic(topic)
+	if len(got.People) != 2 || got.People[0].Author != "b" || got.People[1].Author != "c" || got.ParticipantCount != 2 {
+		t.Errorf("got %+v", got.People)
+	}
+	if handles, _ := n.PostHandles(topic); len(handles) != 3 {
+		t.Errorf("got %d Posts", len(handles))
+	}
+}
+
+func TestEditPostRegroupLimits(t *testing.T) {
+	n, topic, posts := testNup(t, "a", "a", "b")
+	if err := n.SetLimits(Limits{MaxTopics: 1, MaxPosts: 10, MaxPostsPerTopic: 10, MaxPeoplePerTopic: 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	err := n.EditPost(posts[0], func(p *Post) error {
+		p.ScreenName = "c"
+		p.Body = "changed"
+		return nil
+	})
+	if !errors.Is(err, ErrTooManyPeople) {
+		t.Errorf("got %v, want ErrTooManyPeople", err)
+	}
+	if post, _ := n.Post(posts[0]); post.ScreenName != "a" || post.Body != "by a" {
+		t.Errorf("a failed edit changed the Post: %+v", post)
+	}
+
+	// The last Post of b can move to a new author, as b's place is freed
+	err = n.EditPost(posts[2], func(p *Post) error {
+		p.ScreenName = "c"
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authors := authorsOf(t, n, topic); authors[2] != "c" {
+		t.Errorf("got %v", authors)
+	}
+
+	err = n.EditPost(posts[2], func(p *Post) error {
+		p.ScreenName = ""
+		return nil
+	})
+	if !errors.Is(err, ErrInvalidPost) {
+		t.Errorf("got %v for an empty author", err)
+	}
+}
+
+func TestEditTopicDuplicates(t *testing.T) {
+	n := InitNup()
+	first, err := n.NewTopic("first")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := n.NewTopic("second")
+	if err != nil {
+		t.Fatal(err)
+	}
+	firstTopic, _ := n.Topic(first)
+
+	for _, edit := range []func(t *Topic){
+		func(t *Topic) { t.Name = "first" },
+		func(t *Topic) { t.TitleId = firstTopic.TitleId },
+	} {
+		err := n.EditTopic(second, func(t *Topic) error {
+			t.EmpathyCount = 99
+			edit(t)
+			return nil
+		})
+		if !errors.Is(err, ErrTopicExists) {
+			t.Errorf("got %v, want ErrTopicExists", err)
+		}
+		if got, _ := n.Topic(second); got.Name != "second" || got.EmpathyCount != 0 {
+			t.Errorf("a failed edit changed the Topic: %+v", got)
+		}
+	}
+
+	// Keeping its own name and title ID is fine
+	err = n.EditTopic(first, func(t *Topic) error {
+		t.EmpathyCount = 5
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := n.NewTopic("second"); !errors.Is(err, ErrTopicExists) {
+		t.Errorf("got %v for a duplicate new Topic", err)
+	}
+}
+
+func TestEditUndoneOnError(t *testing.T) {
+	n, topic, posts := testNup(t, "a", "b")
+	failed := errors.New("failed")
+
+	err := n.EditTopic(topic, func(t *Topic) error {
+		t.Name = "changed"
+		t.People[0].Posts[0].Body = "changed"
+		return failed
+	})
+	if err != failed {
+		t.Errorf("got %v, want the error of fn", err)
+	}
+	if got, _ := n.Topic(topic); got.Name != "test" || got.People[0].Posts[0].Body != "by a" {
+		t.Errorf("a failed edit changed the Topic: %+v", got)
+	}
+
+	err = n.EditPost(posts[1], func(p *Post) error {
+		p.Body = "changed"
+		p.ScreenName = "a"
+		return failed
+	})
+	if err != failed {
+		t.Errorf("got %v, want the error of fn", err)
+	}
+	if post, _ := n.Post(posts[1]); post.Body != "by b" || post.ScreenName != "b" {
+		t.Errorf("a failed edit changed the Post: %+v", post)
+	}
+}
@@ -0,0 +1,37 @@
+package libwara
+
+import (
+	"crypto/md5"
+	"image"
+	"image/color"
+
+	"golang.org/x/image/draw"
+)
+
+// Draws a 5x5 mirrored identicon from a seed and encodes it as a Topic icon
+// The same seed always gives the same icon
+func CreateIdenticon(seed string) (string, error) {
+	hash := md5.Sum([]byte(seed))
+
+	const size, grid, margin = 128, 5, 14
+	cell := (size - 2*margin) / grid
+
+	fg := color.NRGBA{R: hash[0], G: hash[1], B: hash[2], A: 0xFF}
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(img, img.Bounds(), &image.Uniform{color.White}, image.Point{}, draw.Src)
+
+	for y := 0; y < grid; y++ {
+		for x := 0; x < (grid+1)/2; x++ {
+			bit := y*3 + x
+			if (hash[3+bit/8]>>(bit%8))&0x01 == 0 {
+				continue
+			}
+			for _, col := range []int{x, grid - 1 - x} {
+				r := image.Rect(margin+col*cell, margin+y*cell, margin+(col+1)*cell, margin+(y+1)*cell)
+				draw.Draw(img, r, &image.Uniform{fg}, image.Point{}, draw.Src)
+			}
+		}
+	}
+
+	return encodeImage(img)
+}
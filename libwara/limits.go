@@ -0,0 +1,167 @@
+package libwara
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Defaults of DefaultLimits. No limits of the console are documented, so these are conservative picks, not known caps.
+// MAX_TOPICS is the number of default title IDs, MAX_POSTS is the cap libwara always had, and the per-Topic caps keep
+// the Posts spread over several Topics. Set other limits with SetLimits, or the limits key of a build config
+const MAX_TOPICS uint = 10
+const MAX_POSTS_PER_TOPIC uint = 30
+const MAX_PEOPLE_PER_TOPIC uint = 30
+
+// Longest Post body WithBody accepts, in characters. Like the limits above this is a conservative pick, not a known cap
+const MAX_BODY_LENGTH uint = 255
+
+// Errors returned when a Nup would break one of its Limits
+var (
+	ErrTooManyTopics = errors.New("too many Topics")
+	ErrTooManyPosts  = errors.New("too many Posts")
+	ErrTopicFull     = errors.New("too many Posts in Topic")
+	ErrTooManyPeople = errors.New("too many people in Topic")
+	ErrOutOfRange    = errors.New("index out of range")
+)
+
+// Caps on the size of a Nup
+type Limits struct {
+	MaxTopics         uint `json:"max_topics"`           // Number of Topics in the Nup
+	MaxPosts          uint `json:"max_posts"`            // Number of Posts across all Topics
+	MaxPostsPerTopic  uint `json:"max_posts_per_topic"`  // Number of Posts in a single Topic
+	MaxPeoplePerTopic uint `json:"max_people_per_topic"` // Number of different authors in a single Topic
+}
+
+// The limits a Nup enforces unless SetLimits is called, see MAX_TOPICS
+var DefaultLimits = Limits{
+	MaxTopics:         MAX_TOPICS,
+	MaxPosts:          MAX_POSTS,
+	MaxPostsPerTopic:  MAX_POSTS_PER_TOPIC,
+	MaxPeoplePerTopic: MAX_PEOPLE_PER_TOPIC,
+}
+
+// Returns the limits with every field left at 0 set to its DefaultLimits value
+func (l Limits) withDefaults() Limits {
+	if l.MaxTopics == 0 {
+		l.MaxTopics = DefaultLimits.MaxTopics
+	}
+	if l.MaxPosts == 0 {
+		l.MaxPosts = DefaultLimits.MaxPosts
+	}
+	if l.MaxPostsPerTopic == 0 {
+		l.MaxPostsPerTopic = DefaultLimits.MaxPostsPerTopic
+	}
+	if l.MaxPeoplePerTopic == 0 {
+		l.MaxPeoplePerTopic = DefaultLimits.MaxPeoplePerTopic
+	}
+	return l
+}
+
+// Returns the limits raised where the Nup already holds more than they allow
+func (l Limits) raisedTo(n *Nup) Limits {
+	if topics := uint(len(n.Topics)); topics > l.MaxTopics {
+		l.MaxTopics = topics
+	}
+	total := uint(0)
+	for i := range n.Topics {
+		t := &n.Topics[i]
+		posts := t.PostCount()
+		if posts > l.MaxPostsPerTopic {
+			l.MaxPostsPerTopic = posts
+		}
+		if people := uint(len(t.People)); people > l.MaxPeoplePerTopic {
+			l.MaxPeoplePerTopic = people
+		}
+		total += posts
+	}
+	if total > l.MaxPosts {
+		l.MaxPosts = total
+	}
+	return l
+}
+
+// Checks that one more Topic can be added
+func (l Limits) checkAddTopic(n *Nup) error {
+	if uint(len(n.Topics)) >= l.MaxTopics {
+		return fmt.Errorf("%w: limit is %d", ErrTooManyTopics, l.MaxTopics)
+	}
+	return nil
+}
+
+// Checks that a Post by author can be added to a Topic
+func (l Limits) checkAddPost(n *Nup, t *Topic, author string) error {
+	if n.totalPosts >= l.MaxPosts {
+		return fmt.Errorf("%w: limit is %d", ErrTooManyPosts, l.MaxPosts)
+	}
+	if t.PostCount() >= l.MaxPostsPerTopic {
+		return fmt.Errorf("%w %s: limit is %d", ErrTopicFull, t.Name, l.MaxPostsPerTopic)
+	}
+	if t.GetPerson(author) == nil && uint(len(t.People)) >= l.MaxPeoplePerTopic {
+		return fmt.Errorf("%w %s: limit is %d", ErrTooManyPeople, t.Name, l.MaxPeoplePerTopic)
+	}
+	return nil
+}
+
+// Checks a whole Nup against the limits
+func (l Limits) check(n *Nup) error {
+	if uint(len(n.Topics)) > l.MaxTopics {
+		return fmt.Errorf("%w: got %d, limit is %d", ErrTooManyTopics, len(n.Topics), l.MaxTopics)
+	}
+
+	total := uint(0)
+	for i := range n.Topics {
+		t := &n.Topics[i]
+		posts := t.PostCount()
+		if posts > l.MaxPostsPerTopic {
+			return fmt.Errorf("%w %s: got %d, limit is %d", ErrTopicFull, t.Name, posts, l.MaxPostsPerTopic)
+		}
+		if people := uint(len(t.People)); people > l.MaxPeoplePerTopic {
+			return fmt.Errorf("%w %s: got %d, limit is %d", ErrTooManyPeople, t.Name, people, l.MaxPeoplePerTopic)
+		}
+		total += posts
+	}
+	if total > l.MaxPosts {
+		return fmt.Errorf("%w: got %d, limit is %d", ErrTooManyPosts, total, l.MaxPosts)
+	}
+
+	return nil
+}
+
+// Sets the limits the Nup enforces. Fields left at 0 keep their DefaultLimits value
+// Fails if the Nup already breaks the new limits
+func (n *Nup) SetLimits(l Limits) error {
+	if n == nil {
+		return errors.New("no Nup provided")
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	l = l.withDefaults()
+	if err := l.check(n); err != nil {
+		return err
+	}
+	n.limits = l
+	return nil
+}
+
+// Returns the limits the Nup enforces
+// A Nup that was not created with InitNup or ParseNup enforces DefaultLimits
+func (n *Nup) Limits() Limits {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.currentLimits()
+}
+
+// Returns the limits the Nup enforces. The Nup must be locked
+func (n *Nup) currentLimits() Limits {
+	return n.limits.withDefaults()
+}
+
+// Checks the Nup against its limits
+func (n *Nup) Validate() error {
+	if n == nil {
+		return errors.New("no Nup provided")
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.currentLimits().check(n)
+}
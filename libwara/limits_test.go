@@ -0,0 +1,85 @@
+package libwara
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSetLimitsDefaults(t *testing.T) {
+	n := InitNup()
+	if err := n.SetLimits(Limits{MaxPostsPerTopic: 50}); err != nil {
+		t.Fatal(err)
+	}
+	want := DefaultLimits
+	want.MaxPostsPerTopic = 50
+	if got := n.Limits(); got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestNewTopicTitleIdsUsedUp(t *testing.T) {
+	n := InitNup()
+	if err := n.SetLimits(Limits{MaxTopics: uint(len(defaultTitleIds)) + 5}); err != nil {
+		t.Fatal(err)
+	}
+	for i := range defaultTitleIds {
+		if _, err := n.NewTopic(string(rune('a' + i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := n.NewTopic("one more"); !errors.Is(err, ErrTooManyTopics) {
+		t.Errorf("got %v, want ErrTooManyTopics", err)
+	}
+}
+
+func TestParseNupRaisedLimits(t *testing.T) {
+	n := InitNup()
+	if err := n.SetLimits(Limits{MaxPostsPerTopic: MAX_POSTS_PER_TOPIC + 10}); err != nil {
+		t.Fatal(err)
+	}
+	full, err := n.NewTopic("full")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := uint(0); i < MAX_POSTS_PER_TOPIC+5; i++ {
+		if _, err := n.NewPost(full, WithAuthor("a")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	other, err := n.NewTopic("other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := n.NewPost(other, WithAuthor("b")); err != nil {
+		t.Fatal(err)
+	}
+	out, err := n.Render()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The parsed Nup can still be edited, but does not grow past what it holds
+	parsed, err := ParseNup([]byte(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := parsed.Limits().MaxPostsPerTopic; got != MAX_POSTS_PER_TOPIC+5 {
+		t.Errorf("got a limit of %d Posts per Topic", got)
+	}
+	full, _ = parsed.TopicHandle("full")
+	other, _ = parsed.TopicHandle("other")
+	if err := parsed.SortPosts(full, SortByAuthor); err != nil {
+		t.Error(err)
+	}
+	posts, _ := parsed.PostHandles(other)
+	if err := parsed.MovePost(posts[0], full); !errors.Is(err, ErrTopicFull) {
+		t.Errorf("got %v, want ErrTopicFull", err)
+	}
+
+	if _, err := ParseNupWithLimits([]byte(out), DefaultLimits); !errors.Is(err, ErrTopicFull) {
+		t.Errorf("got %v, want ErrTopicFull", err)
+	}
+	if _, err := ParseNupWithLimits([]byte(out), n.Limits()); err != nil {
+		t.Error(err)
+	}
+}
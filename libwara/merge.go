@@ -0,0 +1,131 @@
+package libwara
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// What MergeNups does when Topics of different Nups have the same name
+type MergeStrategy int
+
+const (
+	MergeRename    MergeStrategy = iota // Add the later Topic as "Name (2)", "Name (3)", ...
+	MergePosts                          // Add the Posts of the later Topic to the first one
+	MergeKeepFirst                      // Leave the later Topic out
+)
+
+// Returns the MergeStrategy with a name: "rename", "merge" or "keep-first"
+func MergeStrategyByName(name string) (MergeStrategy, error) {
+	switch strings.ToLower(name) {
+	case "rename":
+		return MergeRename, nil
+	case "merge":
+		return MergePosts, nil
+	case "keep-first":
+		return MergeKeepFirst, nil
+	}
+	return 0, errors.New("unknown merge strategy " + name + ", expected rename, merge or keep-first")
+}
+
+// Combines Nups into a new one, with the Topics of each Nup in order. The Nups are left as they were
+// Topics whose title ID is already taken are given an unused default title ID
+// The result enforces the limits of the first Nup, and the merge fails if it would break them
+// At least one Nup must be given, and none may be nil
+func MergeNups(strategy MergeStrategy, nups ...*Nup) (*Nup, error) {
+	if len(nups) == 0 {
+		return nil, errors.New("no Nups to merge")
+	}
+	for i, n := range nups {
+		if n == nil {
+			return nil, errors.New("Nup " + strconv.Itoa(i+1) + " to merge is nil")
+		}
+	}
+
+	ret := InitNup()
+	if err := ret.SetLimits(nups[0].Limits()); err != nil {
+		return nil, err
+	}
+
+	ret.mu.Lock()
+	defer ret.mu.Unlock()
+	for _, n := range nups {
+		for _, t := range n.snapshot() {
+			if err := ret.mergeTopic(&t, strategy); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return ret, nil
+}
+
+// Returns a copy of every Topic of the Nup
+func (n *Nup) snapshot() []Topic {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	ret := make([]Topic, len(n.Topics))
+	for i := range n.Topics {
+		ret[i] = n.Topics[i].clone()
+	}
+	return ret
+}
+
+// Adds a Topic and its Posts to the Nup, handling a name clash with strategy. The Nup must be locked
+func (n *Nup) mergeTopic(src *Topic, strategy MergeStrategy) error {
+	name := src.Name
+	if existing, err := n.getTopic(name); err == nil {
+		switch strategy {
+		case MergeKeepFirst:
+			return nil
+		case MergePosts:
+			return n.mergePosts(existing, src)
+		case MergeRename:
+			for i := 2; ; i++ {
+				name = src.Name + " (" + strconv.Itoa(i) + ")"
+				if _, err := n.getTopic(name); err != nil {
+					break
+				}
+			}
+		}
+	}
+
+	titleId := src.TitleId
+	if n.hasTitleId(titleId) {
+		titleId = 0
+		for _, id := range defaultTitleIds {
+			if !n.hasTitleId(id) {
+				titleId = id
+				break
+			}
+		}
+		if titleId == 0 {
+			return errors.New("no unused default title IDs left for Topic " + src.Name)
+		}
+	}
+
+	h, err := n.addTopic(name, titleId, src.Icon)
+	if err != nil {
+		return err
+	}
+	t, _ := n.topicByHandle(h)
+	t.CommunityId = src.CommunityId
+	t.IsRecommended = src.IsRecommended
+	t.EmpathyCount = src.EmpathyCount
+	t.HasShopPage = src.HasShopPage
+	t.ModifiedAt = src.ModifiedAt
+	t.Position = src.Position
+
+	return n.mergePosts(t, src)
+}
+
+// Adds copies of the Posts of src to a Topic of the Nup. The Nup must be locked
+func (n *Nup) mergePosts(t *Topic, src *Topic) error {
+	for _, p := range src.Posts() {
+		if _, err := n.addPost(t, *p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
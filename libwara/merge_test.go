@@ -0,0 +1,58 @@
+package libwara
+
+import (
+	"testing"
+)
+
+func TestMergeNupsInvalid(t *testing.T) {
+	n, _, _ := testNup(t, "a")
+	inputs := [][]*Nup{
+		nil,
+		{nil},
+		{n, nil},
+		{nil, n},
+	}
+	for _, nups := range inputs {
+		if _, err := MergeNups(MergeRename, nups...); err == nil {
+			t.Errorf("merged %v", nups)
+		}
+	}
+}
+
+func TestMergeNups(t *testing.T) {
+	a, _, _ := testNup(t, "a")
+	b, _, _ := testNup(t, "b")
+
+	merged, err := MergeNups(MergeRename, a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := merged.TopicHandle("test (2)"); err != nil {
+		t.Error("clashing topic was not renamed")
+	}
+
+	merged, err = MergeNups(MergePosts, a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	topic, err := merged.TopicHandle("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authors := authorsOf(t, merged, topic); len(authors) != 2 {
+		t.Errorf("got %v", authors)
+	}
+}
+
+func TestDiffNupsNil(t *testing.T) {
+	n, _, _ := testNup(t, "a")
+	if d := DiffNups(nil, nil); len(d) != 0 {
+		t.Errorf("got %v", d)
+	}
+	if d := DiffNups(nil, n); len(d) != 1 || d[0].Kind != Added || len(d[0].Posts) != 1 {
+		t.Errorf("got %+v", d)
+	}
+	if d := DiffNups(n, nil); len(d) != 1 || d[0].Kind != Removed {
+		t.Errorf("got %+v", d)
+	}
+}
@@ -2,106 +2,106 @@ package libwara
 
 type Mii [96]byte
 
-// Elements that make up a mii attribute
-type miiAttribute struct {
-	ByteOffset uint   // Number of first byte value appears in
-	BitOffset  byte   // Number of first bit within the byte the value appears in
-	Size       uint   // How many bits the value takes up
-	MinVal     uint64 // Minimum value the attribute can have
-	MaxVal     uint64 // Maximum value the attribute can have
-}
+// Characters in a Mii name or creator name
+const maxNameLength = 10
 
-// List of attributes that make up a mii
-var MiiFormat = []miiAttribute{
-	{0, 0, 8, 0x03, 0x03},         //version
-	{1, 0, 1, 1, 1},               //copy
-	{1, 1, 1, 0, 0},               //profanity
-	{1, 2, 2, 0, 3},               //region lock
-	{1, 4, 2, 0, 3},               //char set
-	{1, 6, 2, 0, 0},               //blank 1
-	{2, 0, 4, 0, 9},               //page 3ds
-	{2, 4, 4, 0, 9},               //slot 3ds
-	{3, 0, 4, 0, 0},               //unknown 1
-	{3, 4, 3, 1, 4},               //device origin
-	{3, 7, 1, 0, 0},               //blank 2
-	{4, 0, 64, 0, 0xFFFFFFFFFFFF}, //system MAC
+// Layout of a 3DS and Wii U Mii
+// Multi-byte values are little endian except for the Mii ID
+var MiiFormat = Layout{
+	Name:     "3DS/Wii U Mii",
+	Size:     96,
+	BitOrder: LSBFirst,
+	Fields: []Field{
+		{Name: "version", Size: 8, Min: 3, Max: 3},
+		{Name: "copy", Offset: 1, Size: 1, Min: 1, Max: 1},
+		{Name: "profanity", Offset: 1, Bit: 1, Size: 1},
+		{Name: "region lock", Offset: 1, Bit: 2, Size: 2, Max: 3},
+		{Name: "char set", Offset: 1, Bit: 4, Size: 2, Max: 3},
+		{Name: "blank 1", Offset: 1, Bit: 6, Size: 2, Kind: Reserved},
+		{Name: "page 3ds", Offset: 2, Size: 4, Max: 9},
+		{Name: "slot 3ds", Offset: 2, Bit: 4, Size: 4, Max: 9},
+		{Name: "unknown 1", Offset: 3, Size: 4, Kind: Reserved},
+		{Name: "device origin", Offset: 3, Bit: 4, Size: 3, Min: 1, Max: 4},
+		{Name: "blank 2", Offset: 3, Bit: 7, Size: 1, Kind: Reserved},
+		{Name: "system MAC", Offset: 4, Size: 64, Max: 0xFFFFFFFFFFFF},
 
-	//swap endian
-	{12, 7, 1, 0, 1},          //normal mii
-	{12, 6, 1, 0, 1},          //ds mii
-	{12, 5, 1, 0, 1},          //non user mii
-	{12, 4, 1, 0, 1},          //is valid
-	{13, 0, 28, 0, 0xFFFFFFF}, //creation time
-	//swap endian
+		// The Mii ID is a big endian 32 bit value
+		{Name: "normal mii", Offset: 12, Bit: 31, Size: 1, Unit: 4, Order: BigEndian, Max: 1},
+		{Name: "ds mii", Offset: 12, Bit: 30, Size: 1, Unit: 4, Order: BigEndian, Max: 1},
+		{Name: "non user mii", Offset: 12, Bit: 29, Size: 1, Unit: 4, Order: BigEndian, Max: 1},
+		{Name: "is valid", Offset: 12, Bit: 28, Size: 1, Unit: 4, Order: BigEndian, Max: 1},
+		{Name: "creation time", Offset: 12, Size: 28, Unit: 4, Order: BigEndian, Max: 0xFFFFFFF},
 
-	{16, 0, 48, 0, 0xFFFFFFFFFFFF}, //device id
-	{22, 0, 16, 0, 0},              //blank 3
-	{24, 0, 1, 0, 1},               //gender
-	{25, 1, 4, 1, 12},              //birth month
-	{24, 5, 5, 1, 31},              //birth day
-	{25, 2, 4, 0, 11},              //favorite color
-	{25, 6, 1, 0, 1},               //favorite
-	{25, 7, 1, 0, 0},               //blank 4
-	{26, 0, 160, 0, 0},             //mii name
-	{46, 0, 8, 0, 127},             //height
-	{47, 0, 8, 0, 127},             //build
-	{48, 0, 1, 0, 1},               //disable share
-	{48, 1, 4, 0, 11},              //face type
-	{48, 5, 3, 0, 6},               //skin color
-	{49, 0, 4, 0, 11},              //wrinkle type
-	{49, 4, 4, 0, 11},              //makeup type
-	{50, 0, 8, 0, 131},             //hair
-	{51, 0, 3, 0, 7},               //hair color
-	{51, 3, 1, 0, 1},               //flip hair
-	{51, 4, 4, 0, 0},               //blank 5
-	{52, 0, 6, 0, 59},              //eye type
-	{52, 6, 3, 0, 5},               //eye color
-	{53, 1, 4, 0, 7},               //eye scale
-	{53, 5, 3, 0, 6},               //eye vert
-	{54, 0, 5, 0, 7},               //eye rot
-	{54, 5, 4, 0, 12},              //eye space
-	{55, 1, 5, 0, 18},              //eye y pos
-	{55, 6, 2, 0, 0},               //blank 6
-	{56, 0, 5, 0, 24},              //eyebrow type
-	{56, 5, 3, 0, 7},               //eyebrow color
-	{57, 0, 4, 0, 8},               //eyebrow scale
-	{57, 4, 3, 0, 6},               //eyebrow vert
-	{57, 7, 1, 0, 0},               //blank 7
-	{58, 0, 4, 0, 11},              //eyebrow rot
-	{58, 4, 1, 0, 0},               //blank 8
-	{58, 5, 4, 0, 12},              //eyebrow space
-	{59, 1, 5, 3, 18},              //eyebrow y pos
-	{59, 6, 2, 0, 0},               //blank 9
-	{60, 0, 5, 0, 17},              //nose type
-	{60, 5, 4, 0, 8},               //nose scale
-	{61, 1, 5, 0, 18},              //nose y pos
-	{61, 6, 2, 0, 0},               //blank 10
-	{62, 0, 6, 0, 35},              //mouth type
-	{62, 6, 3, 0, 4},               //mouth color
-	{63, 1, 4, 0, 8},               //mouth scale
-	{63, 5, 3, 0, 6},               //mouth hor
-	{64, 0, 5, 0, 18},              //mouth y pos
-	{64, 5, 3, 0, 5},               //mustache type
-	{65, 0, 8, 0, 0},               //unknown 2
-	{66, 0, 3, 0, 6},               //beard type
-	{66, 4, 3, 0, 7},               //face hair color
-	{66, 6, 4, 0, 8},               //mustache scale
-	{67, 2, 5, 0, 16},              //mustache y pos
-	{67, 7, 1, 0, 0},               //blank 11
-	{68, 0, 4, 0, 8},               //glasses type
-	{68, 4, 3, 0, 5},               //glasses color
-	{68, 7, 4, 0, 7},               //glasses scale
-	{69, 3, 5, 0, 20},              //glasses y pos
-	{70, 0, 1, 0, 1},               //mole enabled
-	{70, 1, 4, 0, 8},               //mole scale
-	{70, 5, 5, 0, 16},              //mole x pos
-	{71, 2, 5, 0, 30},              //mole y pos
-	{71, 7, 1, 0, 0},               //blank 12
-	{72, 0, 160, 0, 0},             //creator name
-	{92, 0, 16, 0, 0},              //blank 13
+		{Name: "device id", Offset: 16, Size: 48, Max: 0xFFFFFFFFFFFF},
+		{Name: "blank 3", Offset: 22, Size: 16, Kind: Reserved},
+		{Name: "gender", Offset: 24, Size: 1, Max: 1},
+		{Name: "birth month", Offset: 24, Bit: 1, Size: 4, Min: 1, Max: 12},
+		{Name: "birth day", Offset: 24, Bit: 5, Size: 5, Min: 1, Max: 31},
+		{Name: "favorite color", Offset: 25, Bit: 2, Size: 4, Max: 11},
+		{Name: "favorite", Offset: 25, Bit: 6, Size: 1, Max: 1},
+		{Name: "blank 4", Offset: 25, Bit: 7, Size: 1, Kind: Reserved},
+		{Name: "mii name", Offset: 26, Size: 160, Kind: Bytes},
+		{Name: "height", Offset: 46, Size: 8, Max: 127},
+		{Name: "build", Offset: 47, Size: 8, Max: 127},
+		{Name: "disable share", Offset: 48, Size: 1, Max: 1},
+		{Name: "face type", Offset: 48, Bit: 1, Size: 4, Max: 11},
+		{Name: "skin color", Offset: 48, Bit: 5, Size: 3, Max: 6},
+		{Name: "wrinkle type", Offset: 49, Size: 4, Max: 11},
+		{Name: "makeup type", Offset: 49, Bit: 4, Size: 4, Max: 11},
+		{Name: "hair", Offset: 50, Size: 8, Max: 131},
+		{Name: "hair color", Offset: 51, Size: 3, Max: 7},
+		{Name: "flip hair", Offset: 51, Bit: 3, Size: 1, Max: 1},
+		{Name: "blank 5", Offset: 51, Bit: 4, Size: 4, Kind: Reserved},
+		{Name: "eye type", Offset: 52, Size: 6, Max: 59},
+		{Name: "eye color", Offset: 52, Bit: 6, Size: 3, Max: 5},
+		{Name: "eye scale", Offset: 53, Bit: 1, Size: 4, Max: 7},
+		{Name: "eye vert", Offset: 53, Bit: 5, Size: 3, Max: 6},
+		{Name: "eye rot", Offset: 54, Size: 5, Max: 7},
+		{Name: "eye space", Offset: 54, Bit: 5, Size: 4, Max: 12},
+		{Name: "eye y pos", Offset: 55, Bit: 1, Size: 5, Max: 18},
+		{Name: "blank 6", Offset: 55, Bit: 6, Size: 2, Kind: Reserved},
+		{Name: "eyebrow type", Offset: 56, Size: 5, Max: 24},
+		{Name: "eyebrow color", Offset: 56, Bit: 5, Size: 3, Max: 7},
+		{Name: "eyebrow scale", Offset: 57, Size: 4, Max: 8},
+		{Name: "eyebrow vert", Offset: 57, Bit: 4, Size: 3, Max: 6},
+		{Name: "blank 7", Offset: 57, Bit: 7, Size: 1, Kind: Reserved},
+		{Name: "eyebrow rot", Offset: 58, Size: 4, Max: 11},
+		{Name: "blank 8", Offset: 58, Bit: 4, Size: 1, Kind: Reserved},
+		{Name: "eyebrow space", Offset: 58, Bit: 5, Size: 4, Max: 12},
+		{Name: "eyebrow y pos", Offset: 59, Bit: 1, Size: 5, Min: 3, Max: 18},
+		{Name: "blank 9", Offset: 59, Bit: 6, Size: 2, Kind: Reserved},
+		{Name: "nose type", Offset: 60, Size: 5, Max: 17},
+		{Name: "nose scale", Offset: 60, Bit: 5, Size: 4, Max: 8},
+		{Name: "nose y pos", Offset: 61, Bit: 1, Size: 5, Max: 18},
+		{Name: "blank 10", Offset: 61, Bit: 6, Size: 2, Kind: Reserved},
+		{Name: "mouth type", Offset: 62, Size: 6, Max: 35},
+		{Name: "mouth color", Offset: 62, Bit: 6, Size: 3, Max: 4},
+		{Name: "mouth scale", Offset: 63, Bit: 1, Size: 4, Max: 8},
+		{Name: "mouth hor", Offset: 63, Bit: 5, Size: 3, Max: 6},
+		{Name: "mouth y pos", Offset: 64, Size: 5, Max: 18},
+		{Name: "mustache type", Offset: 64, Bit: 5, Size: 3, Max: 5},
+		{Name: "unknown 2", Offset: 65, Size: 8, Kind: Reserved},
+		{Name: "beard type", Offset: 66, Size: 3, Max: 6},
+		{Name: "face hair color", Offset: 66, Bit: 3, Size: 3, Max: 7},
+		{Name: "mustache scale", Offset: 66, Bit: 6, Size: 4, Max: 8},
+		{Name: "mustache y pos", Offset: 67, Bit: 2, Size: 5, Max: 16},
+		{Name: "blank 11", Offset: 67, Bit: 7, Size: 1, Kind: Reserved},
+		{Name: "glasses type", Offset: 68, Size: 4, Max: 8},
+		{Name: "glasses color", Offset: 68, Bit: 4, Size: 3, Max: 5},
+		{Name: "glasses scale", Offset: 68, Bit: 7, Size: 4, Max: 7},
+		{Name: "glasses y pos", Offset: 69, Bit: 3, Size: 5, Max: 20},
+		{Name: "mole enabled", Offset: 70, Size: 1, Max: 1},
+		{Name: "mole scale", Offset: 70, Bit: 1, Size: 4, Max: 8},
+		{Name: "mole x pos", Offset: 70, Bit: 5, Size: 5, Max: 16},
+		{Name: "mole y pos", Offset: 71, Bit: 2, Size: 5, Max: 30},
+		{Name: "blank 12", Offset: 71, Bit: 7, Size: 1, Kind: Reserved},
+		{Name: "creator name", Offset: 72, Size: 160, Kind: Bytes},
+		{Name: "blank 13", Offset: 92, Size: 16, Kind: Reserved},
+		{Name: "crc", Offset: 94, Size: 16, Unit: 2, Order: BigEndian},
+	},
 }
 
-// Indexes of attributes in the MiiFormat list
+// Indexes of attributes in the fields of MiiFormat
 const (
 	versionAttribute int = iota
 	copyAttribute
@@ -187,6 +187,7 @@ const (
 	blank12Attribute
 	creatorNameAttribute
 	blank13Attribute
+	crcAttribute
 )
 
 // Favorite Color values
@@ -0,0 +1,419 @@
+package libwara
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// A Mii and the values it decodes to
+type miiGolden struct {
+	name    string
+	encoded string
+	fields  map[int]uint64
+	miiName string
+	creator string
+}
+
+var miiGoldens = []miiGolden{
+	{
+		// The Mii every Post gets by default, made on a Wii U
+		name:    "default",
+		encoded: defaultMii,
+		fields: map[int]uint64{
+			versionAttribute:       3,
+			copyAttribute:          1,
+			profanityAttribute:     0,
+			deviceOriginAttribute:  4,
+			nonUserMiiAttribute:    1,
+			favoriteColorAttribute: 4,
+			heightAttribute:        70,
+			buildAttribute:         40,
+			disableShareAttribute:  1,
+			faceTypeAttribute:      8,
+			skinColorAttribute:     2,
+			hairAttribute:          31,
+			flipHairAttribute:      1,
+			eyeTypeAttribute:       48,
+			eyeScaleAttribute:      2,
+			eyeVertAttribute:       1,
+			eyeRotAttribute:        4,
+			eyeSpaceAttribute:      3,
+			eyeYPosAttribute:       10,
+			eyebrowTypeAttribute:   2,
+			eyebrowScaleAttribute:  2,
+			eyebrowVertAttribute:   1,
+			eyebrowRotAttribute:    5,
+			eyebrowSpaceAttribute:  2,
+			eyebrowYPosAttribute:   7,
+			noseTypeAttribute:      16,
+			noseScaleAttribute:     2,
+			noseYPosAttribute:      7,
+			mouthTypeAttribute:     31,
+			mouthScaleAttribute:    2,
+			mouthHorPosAttribute:   3,
+			mouthYPosAttribute:     12,
+			faceHairColorAttribute: 5,
+			mustacheScaleAttribute: 4,
+			mustacheYPosAttribute:  11,
+			glassesScaleAttribute:  4,
+			glassesYPosAttribute:   11,
+			moleScaleAttribute:     4,
+			moleXPosAttribute:      2,
+			moleYPosAttribute:      20,
+			crcAttribute:           0xCBD7,
+		},
+		miiName: "NINTENDO",
+		creator: "NINTENDO",
+	},
+	{
+		// CreateRandomMii("seed", "Name", "Creator"). Changes to this Mii change the Mii of every Reddit author
+		name:    "random",
+		encoded: "AAGYQNaFowf0QAAA0QAAAPml9pyMQQAAkG5OAGEAbQBlAAAAAAAAAAAAAAAAAEwPoHZuAGVIRSSkY0ceDRTUMIsAAwhDEWggQwByAGUAYQB0AG8AcgAAAAAAAAAAAAsV",
+		fields: map[int]uint64{
+			versionAttribute:      0,
+			copyAttribute:         1,
+			deviceOriginAttribute: uint64(DeviceWiiU),
+			normalMiiAttribute:    1,
+			dsMiiAttribute:        1,
+			nonUserMiiAttribute:   0,
+			isValidAttribute:      1,
+			creationTimeAttribute: 0x1000000,
+			systemMacAttribute:    0x40F407A385D6,
+		},
+		miiName: "Name",
+		creator: "Creator",
+	},
+}
+
+func TestMiiGoldens(t *testing.T) {
+	for _, g := range miiGoldens {
+		t.Run(g.name, func(t *testing.T) {
+			m, err := InitMii(g.encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for attribute, want := range g.fields {
+				if got := m.getAttribute(attribute); got != want {
+					t.Errorf("%s: got %d, want %d", MiiFormat.Fields[attribute].Name, got, want)
+				}
+			}
+			if got := m.GetMiiName(); got != g.miiName {
+				t.Errorf("mii name: got %q, want %q", got, g.miiName)
+			}
+			if got := m.GetCreatorName(); got != g.creator {
+				t.Errorf("creator name: got %q, want %q", got, g.creator)
+			}
+
+			fixed := *m
+			fixed.FixCRC()
+			if fixed != *m {
+				t.Error("CRC does not match the data")
+			}
+			if got := m.Encode(); got != g.encoded {
+				t.Errorf("encoding changed: got %s", got)
+			}
+		})
+	}
+}
+
+func TestCreateRandomMiiGolden(t *testing.T) {
+	m, err := CreateRandomMii("seed", "Name", "Creator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Encode(); got != miiGoldens[1].encoded {
+		t.Errorf("got %s, want %s", got, miiGoldens[1].encoded)
+	}
+}
+
+// Every value an attribute round trips should be tried at its limits and in between
+func attributeValues(f *Field) []uint64 {
+	lo, hi := uint64(f.Min), uint64(f.Max)
+	if !f.ranged() {
+		lo, hi = 0, f.mask()
+	}
+	return []uint64{lo, lo + (hi-lo)/2, hi}
+}
+
+func TestMiiAttributeRoundTrip(t *testing.T) {
+	for i := range MiiFormat.Fields {
+		f := &MiiFormat.Fields[i]
+		if f.Kind != Unsigned || i == crcAttribute {
+			continue
+		}
+		for _, value := range attributeValues(f) {
+			m, err := InitMii(defaultMii)
+			if err != nil {
+				t.Fatal(err)
+			}
+			before := *m
+
+			if err := m.setAttribute(i, value); err != nil {
+				t.Errorf("%s = %d: %v", f.Name, value, err)
+				continue
+			}
+			if got := m.getAttribute(i); got != value {
+				t.Errorf("%s: set %d, got %d", f.Name, value, got)
+			}
+
+			// No other attribute may change
+			for j := range MiiFormat.Fields {
+				other := &MiiFormat.Fields[j]
+				if j == i || j == crcAttribute || other.Kind == Bytes {
+					continue
+				}
+				if MiiFormat.Get(m[:], other) != MiiFormat.Get(before[:], other) {
+					t.Errorf("setting %s changed %s", f.Name, other.Name)
+				}
+			}
+			if !bytes.Equal(MiiFormat.GetBytes(m[:], &MiiFormat.Fields[miiNameAttribute]), MiiFormat.GetBytes(before[:], &MiiFormat.Fields[miiNameAttribute])) {
+				t.Errorf("setting %s changed the mii name", f.Name)
+			}
+		}
+
+		if f.ranged() && uint64(f.Max) < f.mask() {
+			m := Mii{}
+			if err := m.setAttribute(i, uint64(f.Max)+1); err == nil {
+				t.Errorf("%s accepted %d", f.Name, f.Max+1)
+			}
+		}
+	}
+}
+
+func TestMiiAccessorRoundTrip(t *testing.T) {
+	m, err := InitMii(defaultMii)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checks := []struct {
+		name string
+		set  func() error
+		get  func() uint64
+		want uint64
+	}{
+		{"birth month", func() error { return m.SetBirthMonth(12) }, m.GetBirthMonth, 12},
+		{"birth day", func() error { return m.SetBirthDay(31) }, m.GetBirthDay, 31},
+		{"creation time", func() error { return m.SetCreationTime(0xFFFFFFF) }, m.GetCreationTime, 0xFFFFFFF},
+		{"device id", func() error { return m.SetDeviceID(0xFFFFFFFFFFFF) }, m.GetDeviceID, 0xFFFFFFFFFFFF},
+		{"console MAC", func() error { return m.SetConsoleMAC(0x40F407A385D6) }, m.GetConsoleMAC, 0x40F407A385D6},
+		{"hair", func() error { return m.SetHairType(131) }, m.GetHairType, 131},
+		{"glasses scale", func() error { return m.SetGlassesScale(7) }, m.GetGlassesScale, 7},
+		{"mustache scale", func() error { return m.SetMustacheScale(8) }, m.GetMustacheScale, 8},
+		{"mole x pos", func() error { return m.SetMoleXPosition(16) }, m.GetMoleXPosition, 16},
+	}
+	for _, c := range checks {
+		if err := c.set(); err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if got := c.get(); got != c.want {
+			t.Errorf("%s: got %d, want %d", c.name, got, c.want)
+		}
+	}
+
+	m.SetNormalMii(false)
+	m.SetValid(true)
+	if m.IsNormalMii() || !m.IsValid() {
+		t.Error("Mii ID flags did not round trip")
+	}
+	if m.GetCreationTime() != 0xFFFFFFF {
+		t.Error("setting Mii ID flags changed the creation time")
+	}
+	if m.GetDeviceID() != 0xFFFFFFFFFFFF {
+		t.Error("creation time overlaps the device id")
+	}
+}
+
+func TestMiiNames(t *testing.T) {
+	names := []string{"", "A", "NINTENDO", "0123456789", "Ünïcødé", "マリオ", "😀😀😀😀😀"}
+	for _, name := range names {
+		m, err := InitMii(defaultMii)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := m.SetMiiName(name); err != nil {
+			t.Errorf("%q: %v", name, err)
+			continue
+		}
+		if err := m.SetCreatorName(name); err != nil {
+			t.Errorf("%q: %v", name, err)
+			continue
+		}
+		if got := m.GetMiiName(); got != name {
+			t.Errorf("mii name: got %q, want %q", got, name)
+		}
+		if got := m.GetCreatorName(); got != name {
+			t.Errorf("creator name: got %q, want %q", got, name)
+		}
+	}
+
+	// Names are UTF-16 little endian, padded with zeros
+	m := Mii{}
+	if err := m.SetMiiName("Ab"); err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{'A', 0, 'b', 0, 0, 0}
+	if !bytes.Equal(m[26:32], want) {
+		t.Errorf("got % x, want % x", m[26:32], want)
+	}
+
+	// A longer name must not leave characters behind
+	if err := m.SetMiiName("ABCDEFGHIJ"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.SetMiiName("xy"); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.GetMiiName(); got != "xy" {
+		t.Errorf("got %q, want %q", got, "xy")
+	}
+
+	for _, name := range []string{"ABCDEFGHIJK", "😀😀😀😀😀😀"} {
+		if err := m.SetMiiName(name); err == nil {
+			t.Errorf("%q accepted", name)
+		}
+	}
+}
+
+func TestConvertTga(t *testing.T) {
+	// A 2x2 image whose bounds do not start at the origin, with a half transparent pixel
+	img := image.NewNRGBA(image.Rect(5, 7, 7, 9))
+	img.SetNRGBA(5, 7, color.NRGBA{R: 0x10, G: 0x20, B: 0x30, A: 0xFF})
+	img.SetNRGBA(6, 7, color.NRGBA{R: 0x40, G: 0x50, B: 0x60, A: 0x80})
+	img.SetNRGBA(5, 8, color.NRGBA{R: 0x70, G: 0x80, B: 0x90, A: 0xFF})
+	img.SetNRGBA(6, 8, color.NRGBA{})
+
+	buf := bytes.Buffer{}
+	if err := convertTga(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	header := Header{}
+	if err := binary.Read(bytes.NewReader(data), binary.LittleEndian, &header); err != nil {
+		t.Fatal(err)
+	}
+	if header.ImageType != 0x02 || header.PixelDepth != 32 || header.ImageWidth != 2 || header.ImageHeight != 2 {
+		t.Fatalf("bad header %+v", header)
+	}
+
+	// Bottom row first, BGRA with straight alpha
+	want := []byte{
+		0x90, 0x80, 0x70, 0xFF, 0x00, 0x00, 0x00, 0x00,
+		0x30, 0x20, 0x10, 0xFF, 0x60, 0x50, 0x40, 0x80,
+	}
+	pixels := data[tgaHeaderSize : tgaHeaderSize+len(want)]
+	if !bytes.Equal(pixels, want) {
+		t.Errorf("got  % x\nwant % x", pixels, want)
+	}
+	if !bytes.Equal(data[tgaHeaderSize+len(want):], Footer) {
+		t.Error("missing TGA footer")
+	}
+
+	// The pixels must come back through the decoder
+	decoded, err := decodeTga(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := color.NRGBAModel.Convert(decoded.At(1, 0)).(color.NRGBA)
+	if got != (color.NRGBA{R: 0x40, G: 0x50, B: 0x60, A: 0x80}) {
+		t.Errorf("decoded %v", got)
+	}
+}
+
+func TestConvertTgaPremultiplied(t *testing.T) {
+	// Premultiplied colors must be written unpremultiplied
+	img := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	img.SetRGBA(0, 0, color.RGBA{R: 0x40, G: 0x20, B: 0x10, A: 0x80})
+
+	buf := bytes.Buffer{}
+	if err := convertTga(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	pixel := buf.Bytes()[tgaHeaderSize : tgaHeaderSize+4]
+	want := []byte{0x1F, 0x3F, 0x7F, 0x80}
+	if !bytes.Equal(pixel, want) {
+		t.Errorf("got % x, want % x", pixel, want)
+	}
+}
+
+// Calls every getter of a Mii, returning their results by name
+func miiGetters(m *Mii) map[string]interface{} {
+	ret := map[string]interface{}{}
+	v := reflect.ValueOf(m)
+	for i := 0; i < v.NumMethod(); i++ {
+		method := v.Type().Method(i)
+		if method.Type.NumIn() != 1 || method.Type.NumOut() != 1 {
+			continue
+		}
+		if !strings.HasPrefix(method.Name, "Get") && !strings.HasPrefix(method.Name, "Is") && !strings.HasPrefix(method.Name, "Has") {
+			continue
+		}
+		ret[method.Name] = v.Method(i).Call(nil)[0].Interface()
+	}
+	return ret
+}
+
+func FuzzMii(f *testing.F) {
+	for _, encoded := range []string{defaultMii, miiGoldens[len(miiGoldens)-1].encoded} {
+		data, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+		f.Add(data[:len(data)-1])
+	}
+	random, err := CreateRandomMii("fuzz", "Name", "Creator")
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(random[:])
+	f.Add(bytes.Repeat([]byte{0xFF}, len(Mii{})))
+	f.Add([]byte{})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		m, err := InitMii(base64.StdEncoding.EncodeToString(data))
+		if len(data) != len(Mii{}) {
+			if err == nil {
+				t.Fatalf("decoded a Mii from %d bytes", len(data))
+			}
+			return
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		getters := miiGetters(m)
+		for _, name := range []string{"GetMiiName", "GetCreatorName"} {
+			if s := getters[name].(string); !utf8.ValidString(s) {
+				t.Errorf("%s returned invalid UTF-8 %q", name, s)
+			}
+		}
+
+		again, err := InitMii(m.Encode())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *again != *m || !bytes.Equal(again[:], data) {
+			t.Fatal("encoding changed the Mii")
+		}
+		if !reflect.DeepEqual(miiGetters(again), getters) {
+			t.Error("getters changed after encoding")
+		}
+
+		// Fixing the CRC only changes the CRC
+		fixed := *m
+		fixed.FixCRC()
+		if !bytes.Equal(fixed[:94], m[:94]) {
+			t.Error("FixCRC changed more than the CRC")
+		}
+	})
+}
@@ -0,0 +1,137 @@
+package libwara
+
+// Layout of a Wii Mii, as stored in RFL_DB.dat and shared over WiiConnect24
+// Every value is big endian, with bits numbered from the most significant bit of their unit
+// Names are 10 UTF-16 big endian characters
+var WiiMiiFormat = Layout{
+	Name:     "Wii Mii",
+	Size:     74,
+	BitOrder: MSBFirst,
+	Fields: []Field{
+		{Name: "invalid", Offset: 0x00, Size: 1, Unit: 2, Order: BigEndian},
+		{Name: "gender", Offset: 0x00, Bit: 1, Size: 1, Unit: 2, Order: BigEndian},
+		{Name: "birth month", Offset: 0x00, Bit: 2, Size: 4, Unit: 2, Order: BigEndian},
+		{Name: "birth day", Offset: 0x00, Bit: 6, Size: 5, Unit: 2, Order: BigEndian},
+		{Name: "favorite color", Offset: 0x00, Bit: 11, Size: 4, Unit: 2, Order: BigEndian},
+		{Name: "favorite", Offset: 0x00, Bit: 15, Size: 1, Unit: 2, Order: BigEndian},
+		{Name: "mii name", Offset: 0x02, Size: 160, Kind: Bytes},
+		{Name: "height", Offset: 0x16, Size: 8},
+		{Name: "build", Offset: 0x17, Size: 8},
+		{Name: "mii id", Offset: 0x18, Size: 32, Order: BigEndian},
+		{Name: "system id", Offset: 0x1C, Size: 32, Order: BigEndian},
+		{Name: "face type", Offset: 0x20, Size: 3, Unit: 2, Order: BigEndian},
+		{Name: "skin color", Offset: 0x20, Bit: 3, Size: 3, Unit: 2, Order: BigEndian},
+		{Name: "face feature", Offset: 0x20, Bit: 6, Size: 4, Unit: 2, Order: BigEndian},
+		{Name: "unknown 1", Offset: 0x20, Bit: 10, Size: 3, Unit: 2, Order: BigEndian, Kind: Reserved},
+		{Name: "disable mingle", Offset: 0x20, Bit: 13, Size: 1, Unit: 2, Order: BigEndian},
+		{Name: "unknown 2", Offset: 0x20, Bit: 14, Size: 1, Unit: 2, Order: BigEndian, Kind: Reserved},
+		{Name: "downloaded", Offset: 0x20, Bit: 15, Size: 1, Unit: 2, Order: BigEndian},
+		{Name: "hair", Offset: 0x22, Size: 7, Unit: 2, Order: BigEndian},
+		{Name: "hair color", Offset: 0x22, Bit: 7, Size: 3, Unit: 2, Order: BigEndian},
+		{Name: "flip hair", Offset: 0x22, Bit: 10, Size: 1, Unit: 2, Order: BigEndian},
+		{Name: "unknown 3", Offset: 0x22, Bit: 11, Size: 5, Unit: 2, Order: BigEndian, Kind: Reserved},
+		{Name: "eyebrow type", Offset: 0x24, Size: 5, Unit: 4, Order: BigEndian},
+		{Name: "unknown 4", Offset: 0x24, Bit: 5, Size: 1, Unit: 4, Order: BigEndian, Kind: Reserved},
+		{Name: "eyebrow rot", Offset: 0x24, Bit: 6, Size: 4, Unit: 4, Order: BigEndian},
+		{Name: "unknown 5", Offset: 0x24, Bit: 10, Size: 6, Unit: 4, Order: BigEndian, Kind: Reserved},
+		{Name: "eyebrow color", Offset: 0x24, Bit: 16, Size: 3, Unit: 4, Order: BigEndian},
+		{Name: "eyebrow scale", Offset: 0x24, Bit: 19, Size: 4, Unit: 4, Order: BigEndian},
+		{Name: "eyebrow y pos", Offset: 0x24, Bit: 23, Size: 5, Unit: 4, Order: BigEndian},
+		{Name: "eyebrow space", Offset: 0x24, Bit: 28, Size: 4, Unit: 4, Order: BigEndian},
+		{Name: "eye type", Offset: 0x28, Size: 6, Unit: 4, Order: BigEndian},
+		{Name: "unknown 6", Offset: 0x28, Bit: 6, Size: 2, Unit: 4, Order: BigEndian, Kind: Reserved},
+		{Name: "eye rot", Offset: 0x28, Bit: 8, Size: 3, Unit: 4, Order: BigEndian},
+		{Name: "eye y pos", Offset: 0x28, Bit: 11, Size: 5, Unit: 4, Order: BigEndian},
+		{Name: "eye color", Offset: 0x28, Bit: 16, Size: 3, Unit: 4, Order: BigEndian},
+		{Name: "unknown 7", Offset: 0x28, Bit: 19, Size: 1, Unit: 4, Order: BigEndian, Kind: Reserved},
+		{Name: "eye scale", Offset: 0x28, Bit: 20, Size: 3, Unit: 4, Order: BigEndian},
+		{Name: "eye space", Offset: 0x28, Bit: 23, Size: 4, Unit: 4, Order: BigEndian},
+		{Name: "unknown 8", Offset: 0x28, Bit: 27, Size: 5, Unit: 4, Order: BigEndian, Kind: Reserved},
+		{Name: "nose type", Offset: 0x2C, Size: 4, Unit: 2, Order: BigEndian},
+		{Name: "nose scale", Offset: 0x2C, Bit: 4, Size: 4, Unit: 2, Order: BigEndian},
+		{Name: "nose y pos", Offset: 0x2C, Bit: 8, Size: 5, Unit: 2, Order: BigEndian},
+		{Name: "unknown 9", Offset: 0x2C, Bit: 13, Size: 3, Unit: 2, Order: BigEndian, Kind: Reserved},
+		{Name: "mouth type", Offset: 0x2E, Size: 5, Unit: 2, Order: BigEndian},
+		{Name: "mouth color", Offset: 0x2E, Bit: 5, Size: 2, Unit: 2, Order: BigEndian},
+		{Name: "mouth scale", Offset: 0x2E, Bit: 7, Size: 4, Unit: 2, Order: BigEndian},
+		{Name: "mouth y pos", Offset: 0x2E, Bit: 11, Size: 5, Unit: 2, Order: BigEndian},
+		{Name: "glasses type", Offset: 0x30, Size: 4, Unit: 2, Order: BigEndian},
+		{Name: "glasses color", Offset: 0x30, Bit: 4, Size: 3, Unit: 2, Order: BigEndian},
+		{Name: "unknown 10", Offset: 0x30, Bit: 7, Size: 1, Unit: 2, Order: BigEndian, Kind: Reserved},
+		{Name: "glasses scale", Offset: 0x30, Bit: 8, Size: 3, Unit: 2, Order: BigEndian},
+		{Name: "glasses y pos", Offset: 0x30, Bit: 11, Size: 5, Unit: 2, Order: BigEndian},
+		{Name: "mustache type", Offset: 0x32, Size: 2, Unit: 2, Order: BigEndian},
+		{Name: "beard type", Offset: 0x32, Bit: 2, Size: 2, Unit: 2, Order: BigEndian},
+		{Name: "face hair color", Offset: 0x32, Bit: 4, Size: 3, Unit: 2, Order: BigEndian},
+		{Name: "mustache scale", Offset: 0x32, Bit: 7, Size: 4, Unit: 2, Order: BigEndian},
+		{Name: "mustache y pos", Offset: 0x32, Bit: 11, Size: 5, Unit: 2, Order: BigEndian},
+		{Name: "mole enabled", Offset: 0x34, Size: 1, Unit: 2, Order: BigEndian},
+		{Name: "mole scale", Offset: 0x34, Bit: 1, Size: 4, Unit: 2, Order: BigEndian},
+		{Name: "mole y pos", Offset: 0x34, Bit: 5, Size: 5, Unit: 2, Order: BigEndian},
+		{Name: "mole x pos", Offset: 0x34, Bit: 10, Size: 5, Unit: 2, Order: BigEndian},
+		{Name: "unknown 11", Offset: 0x34, Bit: 15, Size: 1, Unit: 2, Order: BigEndian, Kind: Reserved},
+		{Name: "creator name", Offset: 0x36, Size: 160, Kind: Bytes},
+	},
+}
+
+// Layout of a Switch Mii, in the CharInfo format used by the Switch Mii services
+// Every value after the name takes a whole byte. The name is 10 UTF-16 little endian characters followed by a zero
+var SwitchMiiFormat = Layout{
+	Name:     "Switch Mii",
+	Size:     88,
+	BitOrder: LSBFirst,
+	Fields: []Field{
+		{Name: "create id", Offset: 0x00, Size: 128, Kind: Bytes},
+		{Name: "mii name", Offset: 0x10, Size: 176, Kind: Bytes},
+		{Name: "font region", Offset: 0x26, Size: 8},
+		{Name: "favorite color", Offset: 0x27, Size: 8},
+		{Name: "gender", Offset: 0x28, Size: 8},
+		{Name: "height", Offset: 0x29, Size: 8},
+		{Name: "build", Offset: 0x2A, Size: 8},
+		{Name: "type", Offset: 0x2B, Size: 8},
+		{Name: "region move", Offset: 0x2C, Size: 8},
+		{Name: "face type", Offset: 0x2D, Size: 8},
+		{Name: "skin color", Offset: 0x2E, Size: 8},
+		{Name: "wrinkle type", Offset: 0x2F, Size: 8},
+		{Name: "makeup type", Offset: 0x30, Size: 8},
+		{Name: "hair", Offset: 0x31, Size: 8},
+		{Name: "hair color", Offset: 0x32, Size: 8},
+		{Name: "flip hair", Offset: 0x33, Size: 8},
+		{Name: "eye type", Offset: 0x34, Size: 8},
+		{Name: "eye color", Offset: 0x35, Size: 8},
+		{Name: "eye scale", Offset: 0x36, Size: 8},
+		{Name: "eye aspect", Offset: 0x37, Size: 8},
+		{Name: "eye rot", Offset: 0x38, Size: 8},
+		{Name: "eye x pos", Offset: 0x39, Size: 8},
+		{Name: "eye y pos", Offset: 0x3A, Size: 8},
+		{Name: "eyebrow type", Offset: 0x3B, Size: 8},
+		{Name: "eyebrow color", Offset: 0x3C, Size: 8},
+		{Name: "eyebrow scale", Offset: 0x3D, Size: 8},
+		{Name: "eyebrow aspect", Offset: 0x3E, Size: 8},
+		{Name: "eyebrow rot", Offset: 0x3F, Size: 8},
+		{Name: "eyebrow x pos", Offset: 0x40, Size: 8},
+		{Name: "eyebrow y pos", Offset: 0x41, Size: 8},
+		{Name: "nose type", Offset: 0x42, Size: 8},
+		{Name: "nose scale", Offset: 0x43, Size: 8},
+		{Name: "nose y pos", Offset: 0x44, Size: 8},
+		{Name: "mouth type", Offset: 0x45, Size: 8},
+		{Name: "mouth color", Offset: 0x46, Size: 8},
+		{Name: "mouth scale", Offset: 0x47, Size: 8},
+		{Name: "mouth aspect", Offset: 0x48, Size: 8},
+		{Name: "mouth y pos", Offset: 0x49, Size: 8},
+		{Name: "face hair color", Offset: 0x4A, Size: 8},
+		{Name: "beard type", Offset: 0x4B, Size: 8},
+		{Name: "mustache type", Offset: 0x4C, Size: 8},
+		{Name: "mustache scale", Offset: 0x4D, Size: 8},
+		{Name: "mustache y pos", Offset: 0x4E, Size: 8},
+		{Name: "glasses type", Offset: 0x4F, Size: 8},
+		{Name: "glasses color", Offset: 0x50, Size: 8},
+		{Name: "glasses scale", Offset: 0x51, Size: 8},
+		{Name: "glasses y pos", Offset: 0x52, Size: 8},
+		{Name: "mole type", Offset: 0x53, Size: 8},
+		{Name: "mole scale", Offset: 0x54, Size: 8},
+		{Name: "mole x pos", Offset: 0x55, Size: 8},
+		{Name: "mole y pos", Offset: 0x56, Size: 8},
+		{Name: "reserved", Offset: 0x57, Size: 8, Kind: Reserved},
+	},
+}
@@ -3,85 +3,16 @@ package libwara
 import (
 	"crypto/md5"
 	"encoding/base64"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"math"
 	"strconv"
-	"strings"
+	"unicode/utf16"
 )
 
 // Helper methods
 
-// set multiple bits
-func setBits(b *Mii, byteindex uint, offset uint8, size uint, val uint64) {
-	//we are working on 1 byte, and we do not cross over
-	if uint(offset)+size <= 8 {
-		mask := ^(byte((0xFF >> (8 - size)) << offset))
-		(*b)[byteindex] &= mask
-		//temp := (byte(val) >> (8 - size) << offset)
-		temp := byte(val) << (offset)
-		(*b)[byteindex] |= temp
-		return
-	}
-
-	scratch := val
-	//set bits on first byte
-	mask := ^(byte(0xFF >> offset))
-	(*b)[byteindex] &= mask
-	(*b)[byteindex] |= byte(scratch) >> offset
-	scratch >>= (8 - offset)
-
-	//set full bytes
-	fullBytes := (size - (8 - uint(offset))) / 8
-	for i := uint(0); i < fullBytes; i++ {
-		(*b)[byteindex+1+i] = byte(scratch)
-		scratch >>= 8
-	}
-
-	//set remaining bytes
-	remainder := (size - (8 - uint(offset))) % 8
-	if remainder != 0 {
-		mask = (0xFF >> remainder)
-		(*b)[byteindex+fullBytes+1] &= mask
-		temp := byte(scratch) << byte(8-remainder)
-		(*b)[byteindex+fullBytes+1] |= temp
-	}
-}
-
-func getBits(b *Mii, byteindex uint, offset uint8, size uint) uint64 {
-	//all within same byte
-	if uint(offset)+size <= 8 {
-		mask := (byte((0xFF << (8 - size)) >> offset))
-		temp := uint64(((*b)[byteindex] & mask) << offset)
-		return temp
-	}
-
-	//get bits from first byte
-	mask := ^(byte(0xFF) << (8 - offset))
-	ret := uint64(((*b)[byteindex]&mask)<<offset) >> offset
-
-	//get full bytes
-	fullBytes := (size - uint(8-offset)) / 8
-	for i := uint(0); i < fullBytes; i++ {
-		bits := uint64((*b)[byteindex+1+i])
-		bits <<= ((i * 8) + uint(8-offset))
-		ret |= bits
-	}
-
-	//get remaining bytes
-	remainder := (size - uint(8-offset)) % 8
-	if remainder != 0 {
-		bits := uint64((*b)[byteindex+fullBytes+1])
-		temp := uint64(^byte((0xFF >> (remainder))))
-		bits &= temp
-		bits >>= (8 - remainder)
-		bits <<= ((fullBytes * 8) + (remainder))
-		ret |= bits
-	}
-
-	return ret
-}
-
 // scale an int from one range to another
 // rounds to nearest whole number
 func scale(val, loIn, hiIn, loOut, hiOut uint64) uint64 {
@@ -109,57 +40,35 @@ func scale(val, loIn, hiIn, loOut, hiOut uint64) uint64 {
 	return ret
 }
 
-// Adjusts a value to fall within a provided range
-func adjustValue(b *Mii, byteindex uint, offset byte, size uint, lo, hi uint64) {
-	rawVal := getBits(b, byteindex, offset, size)
-	maxVal := ^(^uint64(0x00) << size)
-	val := scale(rawVal, 0x00, maxVal, lo, hi)
-	setBits(b, byteindex, offset, size, val)
-}
-
 // Sets an attribute
 // Automatically fixes the CRC
 func (m *Mii) setAttribute(attribute int, value uint64) error {
-	attributeStruct := MiiFormat[attribute]
-	if !(attributeStruct.MinVal <= value && value <= attributeStruct.MaxVal) {
-		var msg strings.Builder
-		msg.WriteString("expected value between ")
-		msg.WriteString(strconv.FormatUint(attributeStruct.MinVal, 10))
-		msg.WriteString(" and ")
-		msg.WriteString(strconv.FormatUint(attributeStruct.MaxVal, 10))
-		msg.WriteString(", got ")
-		msg.WriteString(strconv.FormatUint(value, 10))
-		return errors.New(msg.String())
+	if err := MiiFormat.Set(m[:], &MiiFormat.Fields[attribute], value); err != nil {
+		return err
 	}
-	setBits(m, attributeStruct.ByteOffset, attributeStruct.BitOffset, attributeStruct.Size, uint64(value))
 	m.FixCRC()
 	return nil
 }
 
 // Gets the value of an attribute
 func (m *Mii) getAttribute(attribute int) uint64 {
-	attributeStruct := MiiFormat[attribute]
-	value := getBits(m, attributeStruct.ByteOffset, attributeStruct.BitOffset, attributeStruct.Size)
-	return value
+	return MiiFormat.Get(m[:], &MiiFormat.Fields[attribute])
 }
 
 // Sets a flag
 // Automatically fixes the CRC
 func (m *Mii) setFlag(flag int, value bool) {
-	set := 0
+	set := uint64(0)
 	if value {
 		set = 1
 	}
-	attribute := MiiFormat[flag]
-	setBits(m, attribute.ByteOffset, attribute.BitOffset, attribute.Size, uint64(set))
+	MiiFormat.Put(m[:], &MiiFormat.Fields[flag], set)
 	m.FixCRC()
 }
 
 // Gets a flag
 func (m *Mii) getFlag(flag int) bool {
-	attribute := MiiFormat[flag]
-	value := getBits(m, attribute.ByteOffset, attribute.BitOffset, attribute.Size)
-	return value == 1
+	return MiiFormat.Get(m[:], &MiiFormat.Fields[flag]) == 1
 }
 
 // Calculates and sets the CRC for a Mii
@@ -187,8 +96,7 @@ func (m *Mii) FixCRC() {
 		crc = ((crc << 1) ^ flag)
 	}
 
-	m[94] = byte(crc >> 8)
-	m[95] = byte(crc)
+	MiiFormat.Put(m[:], &MiiFormat.Fields[crcAttribute], uint64(crc))
 }
 
 // Mii modification methods
@@ -221,8 +129,7 @@ func (m *Mii) SetVersion(version uint64) error {
 	if version != 0 && version != 3 {
 		return errors.New("expected value 0 or 3, not " + strconv.FormatUint(version, 10))
 	}
-	attribute := MiiFormat[versionAttribute]
-	setBits(m, attribute.ByteOffset, attribute.BitOffset, attribute.Size, version)
+	MiiFormat.Put(m[:], &MiiFormat.Fields[versionAttribute], version)
 
 	m.FixCRC()
 	return nil
@@ -432,45 +339,12 @@ func (m *Mii) IsFavorite() bool {
 
 // Sets the Name attribute of a Mii
 func (m *Mii) SetMiiName(miiName string) error {
-	if len(miiName) > 10 {
-		return errors.New("mii name can have no more than 10 characters, got " + strconv.Itoa(len(miiName)))
-	}
-	offset := int(MiiFormat[miiNameAttribute].ByteOffset)
-	nameBytes := []rune(miiName)
-	r := 0
-	for i := 0; i < 20; i++ {
-		if r >= (len(nameBytes)) {
-			break
-		}
-
-		if i%2 == 0 {
-			m[offset+i] = byte(nameBytes[r])
-		} else {
-			m[offset+i] = byte((nameBytes[r] & 0xFF00) >> 8)
-			r++
-		}
-	}
-
-	m.FixCRC()
-
-	return nil
+	return m.setName(miiNameAttribute, miiName)
 }
 
 // Gets the Name attribute of a Mii
 func (m *Mii) GetMiiName() string {
-	name := []rune{}
-	offset := int(MiiFormat[miiNameAttribute].ByteOffset)
-	r := 0
-	for i := 0; i < 20; i++ {
-		if i%2 == 0 {
-			name[r] = rune(m[offset+i])
-		} else {
-			name[r] |= (rune(m[offset+i]) << 8)
-			r++
-		}
-	}
-
-	return string(name)
+	return m.getName(miiNameAttribute)
 }
 
 // Sets the Height attribute of a Mii
@@ -915,45 +789,47 @@ func (m *Mii) GetMoleYPosition() uint64 {
 
 // Sets the Creator Name attribute of a Mii
 func (m *Mii) SetCreatorName(creatorName string) error {
-	if len(creatorName) > 10 {
-		return errors.New("mii name can have no more than 10 characters, got " + strconv.Itoa(len(creatorName)))
+	return m.setName(creatorNameAttribute, creatorName)
+}
+
+// Gets the Creator Name attribute of a Mii
+func (m *Mii) GetCreatorName() string {
+	return m.getName(creatorNameAttribute)
+}
+
+// Stores a name as up to 10 UTF-16 little endian characters, padded with zeros
+// Automatically fixes the CRC
+func (m *Mii) setName(attribute int, name string) error {
+	units := utf16.Encode([]rune(name))
+	if len(units) > maxNameLength {
+		return errors.New("mii name can have no more than " + strconv.Itoa(maxNameLength) + " characters, got " + strconv.Itoa(len(units)))
 	}
-	offset := int(MiiFormat[creatorNameAttribute].ByteOffset)
-	nameBytes := []rune(creatorName)
-	r := 0
-	for i := 0; i < 20; i++ {
-		if r >= (len(nameBytes)) {
-			break
-		}
 
-		if i%2 == 0 {
-			m[offset+i] = byte(nameBytes[r])
-		} else {
-			m[offset+i] = byte((nameBytes[r] & 0xFF00) >> 8)
-			r++
-		}
+	encoded := make([]byte, 2*len(units))
+	for i, unit := range units {
+		binary.LittleEndian.PutUint16(encoded[2*i:], unit)
+	}
+	if err := MiiFormat.SetBytes(m[:], &MiiFormat.Fields[attribute], encoded); err != nil {
+		return err
 	}
 
 	m.FixCRC()
-
 	return nil
 }
 
-// Gets the Name attribute of a Mii
-func (m *Mii) GetCreatorName() string {
-	name := []rune{}
-	offset := int(MiiFormat[creatorNameAttribute].ByteOffset)
-	r := 0
-	for i := 0; i < 20; i++ {
-		if i%2 == 0 {
-			name[r] = rune(m[offset+i])
-		} else {
-			name[r] |= (rune(m[offset+i]) << 8)
-			r++
+// Reads a name stored as UTF-16 little endian characters, up to the first zero
+func (m *Mii) getName(attribute int) string {
+	encoded := MiiFormat.GetBytes(m[:], &MiiFormat.Fields[attribute])
+	units := make([]uint16, 0, maxNameLength)
+	for i := 0; i < maxNameLength; i++ {
+		unit := binary.LittleEndian.Uint16(encoded[2*i:])
+		if unit == 0 {
+			break
 		}
+		units = append(units, unit)
 	}
 
-	return string(name)
+	return string(utf16.Decode(units))
 }
 
 // Encodes a Mii to a string
@@ -963,7 +839,8 @@ func (m *Mii) Encode() string {
 
 // Creates a mii with a specified Mii Name and Creator Name when provided with a seed (string)
 func CreateRandomMii(seed, miiName, creatorName string) (*Mii, error) {
-	hashData := md5.New().Sum([]byte(seed))
+	hash := md5.Sum([]byte(seed))
+	hashData := hash[:]
 
 	cycle := createBitCycle(uint64(len(hashData)*8), hashData)
 
@@ -1015,10 +892,9 @@ func CreateRandomMii(seed, miiName, creatorName string) (*Mii, error) {
 			continue
 		}
 
-		a := MiiFormat[i]
+		a := &MiiFormat.Fields[i]
 		rawVal := cycle.readBitCycle(uint8(a.Size))
-		maxRawVal := ^(^uint64(0x00) << a.Size)
-		val := scale(rawVal, 0x00, maxRawVal, a.MinVal, a.MaxVal)
+		val := scale(rawVal, 0x00, a.mask(), uint64(a.Min), uint64(a.Max))
 
 		if err = miiData.setAttribute(i, val); err != nil {
 			fmt.Println(i)
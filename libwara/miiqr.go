@@ -0,0 +1,121 @@
+package libwara
+
+import (
+	"crypto/aes"
+	"errors"
+	"image"
+	"strconv"
+
+	"github.com/makiuchi-d/gozxing"
+	gozxingqr "github.com/makiuchi-d/gozxing/qrcode"
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// Width and height in pixels of the QR codes made by ExportMiiQR
+const MII_QR_SIZE = 256
+
+// Key the 3DS and Wii U encrypt Mii QR codes with
+var miiQRKey = []byte{0x59, 0xFC, 0x81, 0x7E, 0x64, 0x46, 0xEA, 0x61, 0x90, 0x34, 0x7B, 0x20, 0xE9, 0xBD, 0xCE, 0x52}
+
+const (
+	miiQRNonceOffset = 12 // The nonce is the 8 bytes of the Mii starting at the Mii ID
+	miiQRNonceSize   = 8
+	miiQRTagSize     = 16
+	miiQRSize        = len(Mii{}) + miiQRTagSize // Nonce, encrypted rest of the Mii and tag
+)
+
+// Returns the AES-CCM cipher for Mii QR codes. The nonce is padded with 4 zero bytes
+func miiQRCipher() (*ccm, error) {
+	block, err := aes.NewCipher(miiQRKey)
+	if err != nil {
+		return nil, err
+	}
+	return newCCM(block, miiQRNonceSize+4, miiQRTagSize)
+}
+
+// Returns the nonce of a Mii QR code padded to the size the cipher takes
+func miiQRNonce(nonce []byte) []byte {
+	return append(append([]byte{}, nonce...), 0, 0, 0, 0)
+}
+
+// Encrypts a Mii into the payload of a Mii QR code
+func encryptMiiQR(m *Mii) ([]byte, error) {
+	c, err := miiQRCipher()
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := m[miiQRNonceOffset : miiQRNonceOffset+miiQRNonceSize]
+	plaintext := append(append([]byte{}, m[:miiQRNonceOffset]...), m[miiQRNonceOffset+miiQRNonceSize:]...)
+	return append(append([]byte{}, nonce...), c.seal(miiQRNonce(nonce), plaintext)...), nil
+}
+
+// Decrypts the payload of a Mii QR code, checking the tag and the CRC of the Mii
+func decryptMiiQR(payload []byte) (*Mii, error) {
+	if len(payload) != miiQRSize {
+		return nil, errors.New("QR code does not hold a Mii: expected " + strconv.Itoa(miiQRSize) + " bytes, got " + strconv.Itoa(len(payload)))
+	}
+	c, err := miiQRCipher()
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := payload[:miiQRNonceSize]
+	plaintext, err := c.open(miiQRNonce(nonce), payload[miiQRNonceSize:])
+	if err != nil {
+		return nil, errors.New("QR code does not hold a Mii: " + err.Error())
+	}
+
+	m := Mii{}
+	copy(m[:], plaintext[:miiQRNonceOffset])
+	copy(m[miiQRNonceOffset:], nonce)
+	copy(m[miiQRNonceOffset+miiQRNonceSize:], plaintext[miiQRNonceOffset:])
+
+	check := m
+	check.FixCRC()
+	if check != m {
+		return nil, errors.New("Mii in QR code has a bad CRC")
+	}
+	return &m, nil
+}
+
+// Makes the QR code a 3DS or Wii U shows for a Mii, which Mii Maker can scan
+func ExportMiiQR(m *Mii) (image.Image, error) {
+	payload, err := encryptMiiQR(m)
+	if err != nil {
+		return nil, err
+	}
+
+	q, err := qrcode.New(string(payload), qrcode.Medium)
+	if err != nil {
+		return nil, err
+	}
+	return q.Image(MII_QR_SIZE), nil
+}
+
+// Reads the Mii from a QR code made by a 3DS, a Wii U or ExportMiiQR, such as a photo or screenshot of one
+func ImportMiiQR(img image.Image) (*Mii, error) {
+	bitmap, err := gozxing.NewBinaryBitmapFromImage(img)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reading bytes as ISO-8859-1 keeps every byte as one character, whatever modes the QR code uses
+	hints := map[gozxing.DecodeHintType]interface{}{
+		gozxing.DecodeHintType_CHARACTER_SET: "ISO-8859-1",
+		gozxing.DecodeHintType_TRY_HARDER:    true,
+	}
+	result, err := gozxingqr.NewQRCodeReader().Decode(bitmap, hints)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := []byte{}
+	for _, r := range result.GetText() {
+		if r > 0xFF {
+			return nil, errors.New("QR code does not hold a Mii")
+		}
+		payload = append(payload, byte(r))
+	}
+	return decryptMiiQR(payload)
+}
@@ -0,0 +1,71 @@
+package libwara
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMiiQRRoundTrip(t *testing.T) {
+	m, err := CreateRandomMii("seed", "Name", "Creator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := ExportMiiQR(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != MII_QR_SIZE || b.Dy() != MII_QR_SIZE {
+		t.Errorf("QR code is %dx%d", b.Dx(), b.Dy())
+	}
+
+	got, err := ImportMiiQR(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *got != *m {
+		t.Errorf("got %s, want %s", got.Encode(), m.Encode())
+	}
+}
+
+func TestMiiQRTampered(t *testing.T) {
+	m, err := CreateRandomMii("seed", "Name", "Creator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, err := encryptMiiQR(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(payload) != miiQRSize {
+		t.Fatalf("got a %d byte payload, want %d", len(payload), miiQRSize)
+	}
+
+	for _, test := range []struct {
+		name  string
+		index int
+	}{
+		{"nonce", 0},
+		{"encrypted Mii", miiQRNonceSize},
+		{"tag", len(payload) - 1},
+	} {
+		tampered := append([]byte{}, payload...)
+		tampered[test.index] ^= 1
+		if _, err := decryptMiiQR(tampered); err == nil || !strings.Contains(err.Error(), "tag") {
+			t.Errorf("got %v for a changed %s, want a tag mismatch", err, test.name)
+		}
+	}
+	if _, err := decryptMiiQR(payload[:len(payload)-1]); err == nil {
+		t.Error("no error for a short payload")
+	}
+
+	// A Mii with a bad CRC is caught even when the tag matches
+	bad := *m
+	bad[len(bad)-1] ^= 1
+	payload, err = encryptMiiQR(&bad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := decryptMiiQR(payload); err == nil || !strings.Contains(err.Error(), "CRC") {
+		t.Errorf("got %v for a bad CRC", err)
+	}
+}
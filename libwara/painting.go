@@ -0,0 +1,113 @@
+package libwara
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+
+	"golang.org/x/image/draw"
+)
+
+// How a painting is reduced to black and white
+type Dither int
+
+const (
+	DitherThreshold      Dither = iota // Each pixel is rounded to black or white
+	DitherFloydSteinberg               // Rounding errors are spread to neighbouring pixels
+	DitherOrdered                      // Pixels are compared against a 4x4 Bayer matrix
+)
+
+// Painting dimensions, the same as a WaraWara message image
+const (
+	PaintingWidth  = 300
+	PaintingHeight = 100
+)
+
+// 4x4 Bayer matrix for ordered dithering
+var bayer4 = [4][4]float64{
+	{0, 8, 2, 10},
+	{12, 4, 14, 6},
+	{3, 11, 1, 9},
+	{15, 7, 13, 5},
+}
+
+// Returns the Dither with a given name, as used in config files
+func DitherByName(name string) (Dither, error) {
+	switch name {
+	case "", "threshold":
+		return DitherThreshold, nil
+	case "floyd-steinberg":
+		return DitherFloydSteinberg, nil
+	case "ordered":
+		return DitherOrdered, nil
+	}
+	return DitherThreshold, errors.New("unknown dither " + name + ", expected threshold, floyd-steinberg or ordered")
+}
+
+// Scales an image to the painting size, reduces it to black and white and encodes it
+// Returns the encoded painting and its size in bytes before compression
+func CreatePainting(img image.Image, dither Dither) (string, int, error) {
+	scaled := image.NewNRGBA(image.Rect(0, 0, PaintingWidth, PaintingHeight))
+	draw.Draw(scaled, scaled.Bounds(), &image.Uniform{color.White}, image.Point{}, draw.Src)
+	draw.ApproxBiLinear.Scale(scaled, scaled.Rect, img, img.Bounds(), draw.Over, nil)
+
+	// Grey levels between 0 (black) and 1 (white)
+	grey := make([][]float64, PaintingHeight)
+	for y := range grey {
+		grey[y] = make([]float64, PaintingWidth)
+		for x := range grey[y] {
+			g := color.GrayModel.Convert(scaled.At(x, y)).(color.Gray)
+			grey[y][x] = float64(g.Y) / 255
+		}
+	}
+
+	out := image.NewNRGBA(scaled.Rect)
+	for y := 0; y < PaintingHeight; y++ {
+		for x := 0; x < PaintingWidth; x++ {
+			threshold := 0.5
+			if dither == DitherOrdered {
+				threshold = (bayer4[y%4][x%4] + 0.5) / 16
+			}
+
+			val := 0.0
+			if grey[y][x] >= threshold {
+				val = 1
+			}
+			if dither == DitherFloydSteinberg {
+				spreadError(grey, x, y, grey[y][x]-val)
+			}
+
+			c := color.NRGBA{A: 0xFF}
+			if val == 1 {
+				c = color.NRGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}
+			}
+			out.SetNRGBA(x, y, c)
+		}
+	}
+
+	var tga bytes.Buffer
+	if err := convertTga(&tga, out); err != nil {
+		return "", 0, err
+	}
+	size := tga.Len()
+	content, err := convertToNintendo(tga)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return content, size, nil
+}
+
+// Spreads the rounding error of a pixel over the pixels that have not been rounded yet
+func spreadError(grey [][]float64, x, y int, err float64) {
+	add := func(x, y int, weight float64) {
+		if y < len(grey) && x >= 0 && x < len(grey[y]) {
+			grey[y][x] += err * weight
+		}
+	}
+	add(x+1, y, 7.0/16)
+	add(x-1, y+1, 3.0/16)
+	add(x, y+1, 5.0/16)
+	add(x+1, y+1, 1.0/16)
+}
@@ -0,0 +1,121 @@
+package libwara
+
+import (
+	"hash/fnv"
+	"image"
+	"image/color"
+	"math"
+	"strings"
+	"unicode"
+
+	"golang.org/x/image/draw"
+)
+
+// Draws a 128x128 Topic icon showing the initials of a name on a colour picked from the name
+// The same name always gives the same icon. framed adds a darker border around the icon
+func CreatePlaceholderIcon(name string, framed bool) (string, error) {
+	const size, pixel, frame = 128, 8, 8
+
+	bg := nameColor(name)
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	if framed {
+		draw.Draw(img, img.Bounds(), &image.Uniform{darken(bg, 0.6)}, image.Point{}, draw.Src)
+		draw.Draw(img, image.Rect(frame, frame, size-frame, size-frame), &image.Uniform{bg}, image.Point{}, draw.Src)
+	} else {
+		draw.Draw(img, img.Bounds(), &image.Uniform{bg}, image.Point{}, draw.Src)
+	}
+
+	fg := color.NRGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}
+	if luminance(bg) > 0.6 {
+		fg = color.NRGBA{R: 0x20, G: 0x20, B: 0x20, A: 0xFF}
+	}
+
+	letters := []rune(initials(name))
+	width := len(letters)*5*pixel + (len(letters)-1)*pixel
+	x := (size - width) / 2
+	y := (size - 7*pixel) / 2
+	for _, l := range letters {
+		glyph, ok := iconFont[l]
+		if !ok {
+			glyph = iconFont['?']
+		}
+		for row := 0; row < 7; row++ {
+			for col := 0; col < 5; col++ {
+				if glyph[row]&(0x10>>col) == 0 {
+					continue
+				}
+				r := image.Rect(x+col*pixel, y+row*pixel, x+(col+1)*pixel, y+(row+1)*pixel)
+				draw.Draw(img, r, &image.Uniform{fg}, image.Point{}, draw.Src)
+			}
+		}
+		x += 6 * pixel
+	}
+
+	return encodeImage(img)
+}
+
+// Returns up to two upper case initials of a name
+// Words are split on anything that is not a letter or digit, and on lower to upper case changes, so
+// "r/SplatoonMemes" and "splatoon memes" both give "SM"
+func initials(name string) string {
+	name = strings.TrimPrefix(strings.TrimPrefix(name, "/"), "r/")
+
+	ret := []rune{}
+	prev := ' '
+	for _, r := range name {
+		wordStart := (unicode.IsLetter(r) || unicode.IsDigit(r)) &&
+			(!unicode.IsLetter(prev) && !unicode.IsDigit(prev) || unicode.IsLower(prev) && unicode.IsUpper(r))
+		if wordStart {
+			ret = append(ret, unicode.ToUpper(r))
+		}
+		prev = r
+	}
+
+	if len(ret) > 2 {
+		ret = ret[:2]
+	}
+	if len(ret) == 0 {
+		return "?"
+	}
+	return string(ret)
+}
+
+// Picks a colour from a hash of the name, varying the hue with a fixed saturation and brightness
+func nameColor(name string) color.NRGBA {
+	h := fnv.New32a()
+	h.Write([]byte(strings.ToLower(name)))
+	hue := float64(h.Sum32()%360) / 60
+
+	const saturation, value = 0.55, 0.85
+	c := value * saturation
+	x := c * (1 - math.Abs(math.Mod(hue, 2)-1))
+	m := value - c
+
+	var r, g, b float64
+	switch int(hue) {
+	case 0:
+		r, g, b = c, x, 0
+	case 1:
+		r, g, b = x, c, 0
+	case 2:
+		r, g, b = 0, c, x
+	case 3:
+		r, g, b = 0, x, c
+	case 4:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+
+	return color.NRGBA{R: uint8((r + m) * 255), G: uint8((g + m) * 255), B: uint8((b + m) * 255), A: 0xFF}
+}
+
+// Scales the brightness of a colour
+func darken(c color.NRGBA, factor float64) color.NRGBA {
+	return color.NRGBA{R: uint8(float64(c.R) * factor), G: uint8(float64(c.G) * factor), B: uint8(float64(c.B) * factor), A: c.A}
+}
+
+// Relative luminance of a colour, between 0 and 1
+func luminance(c color.NRGBA) float64 {
+	return (0.2126*float64(c.R) + 0.7152*float64(c.G) + 0.0722*float64(c.B)) / 255
+}
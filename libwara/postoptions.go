@@ -0,0 +1,123 @@
+package libwara
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"time"
+	"unicode/utf8"
+)
+
+// Returned when a PostOption is given a value the console cannot show
+var ErrInvalidPost = errors.New("invalid Post")
+
+// Sets up a Post before it is added to a Topic, see AddPost and NewPost
+type PostOption func(p *Post) error
+
+// Sets the text of the Post. Fails if the body is longer than MAX_BODY_LENGTH characters
+func WithBody(body string) PostOption {
+	return func(p *Post) error {
+		if length := uint(utf8.RuneCountInString(body)); length > MAX_BODY_LENGTH {
+			return fmt.Errorf("%w: body is %d characters, limit is %d", ErrInvalidPost, length, MAX_BODY_LENGTH)
+		}
+		p.Body = body
+		return nil
+	}
+}
+
+// Sets the author of the Post. Posts by the same author are grouped under one Person
+func WithAuthor(author string) PostOption {
+	return func(p *Post) error {
+		if author == "" {
+			return fmt.Errorf("%w: empty author", ErrInvalidPost)
+		}
+		p.ScreenName = author
+		return nil
+	}
+}
+
+// Sets the Mii shown next to the Post
+func WithMii(m *Mii) PostOption {
+	return func(p *Post) error {
+		if m == nil {
+			return fmt.Errorf("%w: no Mii provided", ErrInvalidPost)
+		}
+		p.MiiData = m.Encode()
+		return nil
+	}
+}
+
+// Sets the Feeling of the Post, which picks the face of its Mii
+func WithFeeling(f Feeling) PostOption {
+	return func(p *Post) error {
+		if f < FEELING_DEFAULT || f > FEELING_SAD {
+			return fmt.Errorf("%w: unknown feeling %d", ErrInvalidPost, f)
+		}
+		p.FeelingId = f
+		return nil
+	}
+}
+
+// Turns an image into the painting of the Post
+// If a Dither is provided it is used instead of DitherFloydSteinberg
+func WithPainting(img image.Image, dither ...Dither) PostOption {
+	return func(p *Post) error {
+		if img == nil {
+			return fmt.Errorf("%w: no image provided", ErrInvalidPost)
+		}
+		d := DitherFloydSteinberg
+		if len(dither) > 0 {
+			d = dither[0]
+		}
+
+		content, size, err := CreatePainting(img, d)
+		if err != nil {
+			return err
+		}
+		p.PaintingFormat = "tga"
+		p.PaintingContent = content
+		p.PaintingSize = size
+		return nil
+	}
+}
+
+// Sets when the Post was made
+func WithCreatedAt(t time.Time) PostOption {
+	return func(p *Post) error {
+		if t.IsZero() {
+			return fmt.Errorf("%w: no creation time provided", ErrInvalidPost)
+		}
+		p.CreatedAt = t.Format("2006-01-02 15:04:05")
+		return nil
+	}
+}
+
+// Hides the Post behind a spoiler warning
+func WithSpoiler() PostOption {
+	return func(p *Post) error {
+		p.IsSpoiler = 1
+		return nil
+	}
+}
+
+// Returns a blank Post by "Blank" with every option applied, or the first error an option returns
+func buildPost(opts []PostOption) (Post, error) {
+	p := Post{
+		Body:        "Blank post",
+		CountryId:   CountryJapan, // The country ID blank Posts always had, builds set their own
+		CreatedAt:   time.Now().Format("2006-01-02 15:04:05"),
+		FeelingId:   FEELING_DEFAULT,
+		LanguageId:  LanguageEnglish,
+		MiiData:     defaultMii,
+		PlatformId:  PlatformWiiU,
+		RegionId:    RegionIdUSA,
+		ScreenName:  "Blank",
+		PaintingUrl: "http://botu",
+	}
+	for _, opt := range opts {
+		if err := opt(&p); err != nil {
+			return Post{}, err
+		}
+	}
+	return p, nil
+}
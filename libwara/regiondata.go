@@ -0,0 +1,121 @@
+package libwara
+
+// Nintendo language IDs
+type LanguageId int
+
+const (
+	LanguageJapanese LanguageId = iota
+	LanguageEnglish
+	LanguageFrench
+	LanguageGerman
+	LanguageItalian
+	LanguageSpanish
+	LanguageChineseSimplified
+	LanguageKorean
+	LanguageDutch
+	LanguagePortuguese
+	LanguageRussian
+	LanguageChineseTraditional
+)
+
+// Nintendo country IDs
+type CountryId int
+
+const (
+	CountryJapan         CountryId = 1
+	CountryBrazil        CountryId = 16
+	CountryCanada        CountryId = 18
+	CountryMexico        CountryId = 36
+	CountryUnitedStates  CountryId = 49
+	CountryAustralia     CountryId = 65
+	CountryAustria       CountryId = 66
+	CountryBelgium       CountryId = 67
+	CountryDenmark       CountryId = 74
+	CountryFinland       CountryId = 76
+	CountryFrance        CountryId = 77
+	CountryGermany       CountryId = 78
+	CountryIreland       CountryId = 82
+	CountryItaly         CountryId = 83
+	CountryNetherlands   CountryId = 94
+	CountryNewZealand    CountryId = 95
+	CountryNorway        CountryId = 96
+	CountryPoland        CountryId = 97
+	CountryPortugal      CountryId = 98
+	CountryRussia        CountryId = 100
+	CountrySpain         CountryId = 105
+	CountrySweden        CountryId = 107
+	CountrySwitzerland   CountryId = 108
+	CountryUnitedKingdom CountryId = 110
+)
+
+// Nintendo region IDs, matching the region bits of the console
+type RegionId int
+
+const (
+	RegionIdJPN RegionId = 1
+	RegionIdUSA RegionId = 2
+	RegionIdEUR RegionId = 4
+	RegionIdCHN RegionId = 16
+	RegionIdKOR RegionId = 32
+	RegionIdTWN RegionId = 64
+)
+
+// Nintendo platform IDs
+type PlatformId int
+
+const (
+	Platform3DS  PlatformId = 0
+	PlatformWiiU PlatformId = 1
+)
+
+// ISO 639-1 codes of each language
+var languageCodes = map[string]LanguageId{
+	"ja":    LanguageJapanese,
+	"en":    LanguageEnglish,
+	"fr":    LanguageFrench,
+	"de":    LanguageGerman,
+	"it":    LanguageItalian,
+	"es":    LanguageSpanish,
+	"zh":    LanguageChineseSimplified,
+	"ko":    LanguageKorean,
+	"nl":    LanguageDutch,
+	"pt":    LanguagePortuguese,
+	"ru":    LanguageRussian,
+	"zh-tw": LanguageChineseTraditional,
+}
+
+// ISO 3166-1 alpha-2 codes of each country
+var countryCodes = map[string]CountryId{
+	"JP": CountryJapan,
+	"BR": CountryBrazil,
+	"CA": CountryCanada,
+	"MX": CountryMexico,
+	"US": CountryUnitedStates,
+	"AU": CountryAustralia,
+	"AT": CountryAustria,
+	"BE": CountryBelgium,
+	"DK": CountryDenmark,
+	"FI": CountryFinland,
+	"FR": CountryFrance,
+	"DE": CountryGermany,
+	"IE": CountryIreland,
+	"IT": CountryItaly,
+	"NL": CountryNetherlands,
+	"NZ": CountryNewZealand,
+	"NO": CountryNorway,
+	"PL": CountryPoland,
+	"PT": CountryPortugal,
+	"RU": CountryRussia,
+	"ES": CountrySpain,
+	"SE": CountrySweden,
+	"CH": CountrySwitzerland,
+	"GB": CountryUnitedKingdom,
+}
+
+// Region each Region is released in
+var regionIds = map[Region]RegionId{
+	RegionJPN: RegionIdJPN,
+	RegionUSA: RegionIdUSA,
+	RegionEUR: RegionIdEUR,
+	RegionAll: RegionIdJPN | RegionIdUSA | RegionIdEUR,
+}
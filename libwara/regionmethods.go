@@ -0,0 +1,61 @@
+package libwara
+
+import (
+	"errors"
+	"strings"
+)
+
+// Returns the language with an ISO 639-1 code, such as "en"
+func LanguageByCode(code string) (LanguageId, error) {
+	if l, ok := languageCodes[strings.ToLower(code)]; ok {
+		return l, nil
+	}
+	return 0, errors.New("unknown language code " + code)
+}
+
+// Returns the ISO 639-1 code of a language
+func (l LanguageId) Code() string {
+	for code, id := range languageCodes {
+		if id == l {
+			return code
+		}
+	}
+	return ""
+}
+
+// Returns the country with an ISO 3166-1 alpha-2 code, such as "US"
+func CountryByCode(code string) (CountryId, error) {
+	if c, ok := countryCodes[strings.ToUpper(code)]; ok {
+		return c, nil
+	}
+	return 0, errors.New("unknown country code " + code)
+}
+
+// Returns the ISO 3166-1 alpha-2 code of a country
+func (c CountryId) Code() string {
+	for code, id := range countryCodes {
+		if id == c {
+			return code
+		}
+	}
+	return ""
+}
+
+// Returns the Nintendo region ID of a Region
+func (r Region) Id() (RegionId, error) {
+	if id, ok := regionIds[Region(strings.ToUpper(string(r)))]; ok {
+		return id, nil
+	}
+	return 0, errors.New("unknown region " + string(r))
+}
+
+// Returns the Region a country belongs to
+func (c CountryId) Region() Region {
+	switch {
+	case c == CountryJapan:
+		return RegionJPN
+	case c < CountryAustralia:
+		return RegionUSA
+	}
+	return RegionEUR
+}
@@ -0,0 +1,220 @@
+package libwara
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// What SortPosts orders Posts by
+type SortBy int
+
+const (
+	SortByTime   SortBy = iota // Newest first
+	SortByScore                // Most empathy first, then most replies
+	SortByAuthor               // Screen name, A to Z
+)
+
+// Returns the SortBy with a name: "time", "score" or "author"
+func SortByName(name string) (SortBy, error) {
+	switch strings.ToLower(name) {
+	case "time":
+		return SortByTime, nil
+	case "score":
+		return SortByScore, nil
+	case "author":
+		return SortByAuthor, nil
+	}
+	return 0, errors.New("unknown sort order " + name + ", expected time, score or author")
+}
+
+// Checks if Post a comes before Post b
+func (by SortBy) less(a, b *Post) bool {
+	switch by {
+	case SortByScore:
+		ea, _ := strconv.Atoi(a.EmpathyCount)
+		eb, _ := strconv.Atoi(b.EmpathyCount)
+		if ea != eb {
+			return ea > eb
+		}
+		return a.ReplyCount > b.ReplyCount
+	case SortByAuthor:
+		return strings.ToLower(a.ScreenName) < strings.ToLower(b.ScreenName)
+	}
+	// "2006-01-02 15:04:05" sorts the same as a string and as a time
+	return a.CreatedAt > b.CreatedAt
+}
+
+// Numbers the Topics by Position and the Posts of each Topic by Number, both from 1, in the order they appear
+// The Nup must be locked
+func (n *Nup) renumber() {
+	for i := range n.Topics {
+		t := &n.Topics[i]
+		t.Position = i + 1
+		for j, p := range t.Posts() {
+			p.Number = j + 1
+		}
+	}
+}
+
+// Returns the Person holding a Post of the Topic, or nil if the Post is not in the Topic
+func (t *Topic) personOf(h PostHandle) *Person {
+	for i := range t.People {
+		for j := range t.People[i].Posts {
+			if t.People[i].Posts[j].handle == h {
+				return &t.People[i]
+			}
+		}
+	}
+	return nil
+}
+
+// Orders the Posts of a Topic
+// Posts stay grouped by author: the Posts of each author are sorted, then the authors by their first Post
+// Authors without Posts go last
+func (n *Nup) SortPosts(topic TopicHandle, by SortBy) error {
+	if n == nil {
+		return errors.New("no Nup provided")
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	t, err := n.topicByHandle(topic)
+	if err != nil {
+		return err
+	}
+	for i := range t.People {
+		posts := t.People[i].Posts
+		sort.SliceStable(posts, func(a, b int) bool {
+			return by.less(&posts[a], &posts[b])
+		})
+	}
+	sort.SliceStable(t.People, func(a, b int) bool {
+		pa, pb := t.People[a].Posts, t.People[b].Posts
+		if len(pa) == 0 || len(pb) == 0 {
+			return len(pb) == 0 && len(pa) > 0
+		}
+		return by.less(&pa[0], &pb[0])
+	})
+
+	n.renumber()
+	return nil
+}
+
+// Swaps two Posts by the same author in the same Topic
+// Posts stay grouped by author, so Posts by different authors cannot be swapped. Use SortPosts to reorder authors
+func (n *Nup) SwapPosts(a, b PostHandle) error {
+	if n == nil {
+		return errors.New("no Nup provided")
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	ta, pa, err := n.postByHandle(a)
+	if err != nil {
+		return err
+	}
+	tb, pb, err := n.postByHandle(b)
+	if err != nil {
+		return err
+	}
+	if ta != tb {
+		return errors.New("cannot swap Posts of different Topics " + ta.Name + " and " + tb.Name + ", move one first")
+	}
+
+	if ta.personOf(a) != ta.personOf(b) {
+		return errors.New("cannot swap Posts by different authors " + pa.ScreenName + " and " + pb.ScreenName)
+	}
+	*pa, *pb = *pb, *pa
+
+	n.renumber()
+	return nil
+}
+
+// Moves a Post to the end of another Topic, after any other Posts by its author
+// Fails without changing anything if the Topic cannot take the Post within the limits of the Nup
+func (n *Nup) MovePost(post PostHandle, to TopicHandle) error {
+	if n == nil {
+		return errors.New("no Nup provided")
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	from, p, err := n.postByHandle(post)
+	if err != nil {
+		return err
+	}
+	t, err := n.topicByHandle(to)
+	if err != nil {
+		return err
+	}
+	if from == t {
+		return nil
+	}
+
+	// The Post is removed from the count while it is checked, it only changes Topic
+	n.totalPosts--
+	err = n.currentLimits().checkAddPost(n, t, p.ScreenName)
+	n.totalPosts++
+	if err != nil {
+		return err
+	}
+
+	moved := *p
+	person := from.personOf(post)
+	for i := range from.People {
+		if &from.People[i] != person {
+			continue
+		}
+		for j := range person.Posts {
+			if person.Posts[j].handle == post {
+				n.removePost(from, i, j)
+				break
+			}
+		}
+		break
+	}
+	// addPost takes a new handle, the moved Post keeps its own
+	added, err := n.addPost(t, moved)
+	if err != nil {
+		return err
+	}
+	added.handle = post
+
+	n.renumber()
+	return nil
+}
+
+// Puts the Topics of the Nup in a new order
+// order must hold the handle of every Topic exactly once
+func (n *Nup) ReorderTopics(order []TopicHandle) error {
+	if n == nil {
+		return errors.New("no Nup provided")
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if len(order) != len(n.Topics) {
+		return fmt.Errorf("%w: got %d Topics, the Nup has %d", ErrOutOfRange, len(order), len(n.Topics))
+	}
+	topics := make([]Topic, 0, len(order))
+	seen := map[TopicHandle]bool{}
+	for _, h := range order {
+		if seen[h] {
+			return errors.New("Topic " + strconv.FormatUint(uint64(h), 10) + " is listed more than once")
+		}
+		seen[h] = true
+
+		t, err := n.topicByHandle(h)
+		if err != nil {
+			return err
+		}
+		topics = append(topics, *t)
+	}
+	n.Topics = topics
+
+	n.renumber()
+	return nil
+}
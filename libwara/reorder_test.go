@@ -0,0 +1,86 @@
+package libwara
+
+import (
+	"testing"
+)
+
+// Returns a Nup with one Topic holding a Post by each author, in order
+func testNup(t *testing.T, authors ...string) (*Nup, TopicHandle, []PostHandle) {
+	t.Helper()
+	n := InitNup()
+	topic, err := n.NewTopic("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	posts := []PostHandle{}
+	for _, author := range authors {
+		h, err := n.NewPost(topic, WithAuthor(author), WithBody("by "+author))
+		if err != nil {
+			t.Fatal(err)
+		}
+		posts = append(posts, h)
+	}
+	return n, topic, posts
+}
+
+// Returns the authors of the Posts of a Topic, in order
+func authorsOf(t *testing.T, n *Nup, topic TopicHandle) []string {
+	t.Helper()
+	ret := []string{}
+	err := n.EachPost(topic, func(h PostHandle, p *Post) bool {
+		ret = append(ret, p.ScreenName)
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ret
+}
+
+func TestParseNupEmptyPerson(t *testing.T) {
+	data := []byte(`<result><topics><topic><title_id>1</title_id><community_id>1</community_id><name>t</name>` +
+		`<participant_count>5</participant_count><people>` +
+		`<person><posts></posts></person>` +
+		`<person><posts><post><screen_name>b</screen_name></post></posts></person>` +
+		`<person><posts><post><screen_name>a</screen_name></post></posts></person>` +
+		`</people></topic></topics></result>`)
+	n, err := ParseNup(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	topic, err := n.TopicHandle("t")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, _ := n.Topic(topic)
+	if len(got.People) != 2 || got.ParticipantCount != 2 {
+		t.Fatalf("the empty person should have been dropped, got %d participants in %+v", got.ParticipantCount, got.People)
+	}
+
+	if err := n.SortPosts(topic, SortByAuthor); err != nil {
+		t.Fatal(err)
+	}
+	if authors := authorsOf(t, n, topic); len(authors) != 2 || authors[0] != "a" || authors[1] != "b" {
+		t.Errorf("got %v", authors)
+	}
+}
+
+func TestSwapPosts(t *testing.T) {
+	n, topic, posts := testNup(t, "a", "a", "b")
+
+	if err := n.SwapPosts(posts[0], posts[1]); err != nil {
+		t.Fatal(err)
+	}
+	first, _ := n.PostHandles(topic)
+	if first[0] != posts[1] || first[1] != posts[0] {
+		t.Errorf("posts by the same author were not swapped")
+	}
+
+	if err := n.SwapPosts(posts[0], posts[2]); err == nil {
+		t.Error("swapped posts by different authors")
+	}
+	if authors := authorsOf(t, n, topic); authors[2] != "b" {
+		t.Errorf("a failed swap moved posts: %v", authors)
+	}
+}
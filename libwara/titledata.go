@@ -0,0 +1,32 @@
+package libwara
+
+import (
+	_ "embed"
+)
+
+// Region a title was released in
+type Region string
+
+const (
+	RegionJPN Region = "JPN"
+	RegionUSA Region = "USA"
+	RegionEUR Region = "EUR"
+	RegionAll Region = "ALL"
+)
+
+// A single entry in the title catalog
+// The catalog ships no icons, as those are the artwork of the games. Entries may set one with the icon field
+type Title struct {
+	TitleId     uint   `json:"title_id"`
+	Name        string `json:"name"`
+	Region      Region `json:"region"`
+	Icon        string `json:"icon,omitempty"`        // Encoded icon, a placeholder icon drawn from the name is used when blank
+	Placeholder bool   `json:"placeholder,omitempty"` // The title ID is one of the default IDs of new Topics, not a known game
+}
+
+// Raw catalog data, decoded on first use
+//
+//go:embed titles.json
+var titleCatalogData []byte
+
+var titleCatalog []Title
@@ -0,0 +1,100 @@
+package libwara
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var loadCatalogOnce sync.Once
+
+// Decodes the embedded catalog. The catalog is part of the binary, so a bad catalog is a programming error
+func loadCatalog() {
+	loadCatalogOnce.Do(func() {
+		if err := json.Unmarshal(titleCatalogData, &titleCatalog); err != nil {
+			panic("libwara: invalid title catalog: " + err.Error())
+		}
+	})
+}
+
+// Returns every Title in the catalog
+func Titles() []Title {
+	loadCatalog()
+	ret := make([]Title, len(titleCatalog))
+	copy(ret, titleCatalog)
+	return ret
+}
+
+// Returns every Title whose name contains query, ignoring case
+func SearchTitles(query string) []Title {
+	loadCatalog()
+	query = strings.ToLower(query)
+	ret := []Title{}
+	for _, t := range titleCatalog {
+		if strings.Contains(strings.ToLower(t.Name), query) {
+			ret = append(ret, t)
+		}
+	}
+
+	return ret
+}
+
+// Returns the Title with a given title ID
+func LookupTitleId(titleId uint) (*Title, error) {
+	loadCatalog()
+	for i := range titleCatalog {
+		if titleCatalog[i].TitleId == titleId {
+			t := titleCatalog[i]
+			return &t, nil
+		}
+	}
+
+	return nil, errors.New("no title with ID " + strconv.FormatUint(uint64(titleId), 10))
+}
+
+// Returns the Title with a given game name. If a region is provided, only titles from that region are considered,
+// otherwise the USA release is preferred
+func LookupTitle(name string, region ...Region) (*Title, error) {
+	loadCatalog()
+	var found *Title
+	for i := range titleCatalog {
+		t := &titleCatalog[i]
+		if !strings.EqualFold(t.Name, name) {
+			continue
+		}
+		if len(region) > 0 {
+			if t.Region == region[0] {
+				found = t
+				break
+			}
+			continue
+		}
+		if found == nil || t.Region == RegionUSA {
+			found = t
+		}
+	}
+
+	if found == nil {
+		return nil, errors.New("no title with name " + name)
+	}
+	t := *found
+	return &t, nil
+}
+
+// Returns the icon to use for a topic of this Title
+// Titles without an icon get a placeholder icon showing their name, placeholder Titles the default icon
+func (t *Title) TopicIcon() string {
+	if t.Icon != "" {
+		return t.Icon
+	}
+	if t.Placeholder {
+		return defaultIcon
+	}
+	icon, err := CreatePlaceholderIcon(t.Name, true)
+	if err != nil {
+		return defaultIcon
+	}
+	return icon
+}
@@ -0,0 +1,76 @@
+package libwara
+
+import (
+	"testing"
+)
+
+func TestTitleCatalog(t *testing.T) {
+	seen := map[uint]bool{}
+	for _, title := range Titles() {
+		if seen[title.TitleId] {
+			t.Errorf("title ID %d is in the catalog twice", title.TitleId)
+		}
+		seen[title.TitleId] = true
+		if title.Name == "" {
+			t.Errorf("title ID %d has no name", title.TitleId)
+		}
+		switch title.Region {
+		case RegionJPN, RegionUSA, RegionEUR, RegionAll:
+		default:
+			t.Errorf("%s has region %q", title.Name, title.Region)
+		}
+	}
+
+	for _, id := range defaultTitleIds {
+		title, err := LookupTitleId(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !title.Placeholder {
+			t.Errorf("default title ID %d is not marked as a placeholder", id)
+		}
+	}
+	for _, title := range Titles() {
+		if !title.Placeholder {
+			continue
+		}
+		found := false
+		for _, id := range defaultTitleIds {
+			found = found || id == title.TitleId
+		}
+		if !found {
+			t.Errorf("%s is a placeholder but not a default title ID", title.Name)
+		}
+	}
+}
+
+func TestTitleTopicIcon(t *testing.T) {
+	game, err := LookupTitle("Splatoon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	icon := game.TopicIcon()
+	if icon == defaultIcon {
+		t.Error("a game without an icon got the default icon instead of a placeholder")
+	}
+	img, err := DecodeImage(icon)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 128 || b.Dy() != 128 {
+		t.Errorf("icon is %dx%d", b.Dx(), b.Dy())
+	}
+
+	placeholder, err := LookupTitleId(defaultTitleIds[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if placeholder.TopicIcon() != defaultIcon {
+		t.Error("a placeholder title did not get the default icon")
+	}
+
+	game.Icon = "custom"
+	if game.TopicIcon() != "custom" {
+		t.Error("the icon of the title was not used")
+	}
+}
@@ -2,6 +2,7 @@ package libwara
 
 import (
 	"encoding/xml"
+	"sync"
 )
 
 const MAX_POSTS uint = 260
@@ -19,38 +20,42 @@ const (
 
 // The structure of a single post
 type Post struct {
-	XMLName                    xml.Name `xml:"post"`
-	Body                       string   `xml:"body"`
-	CommunityId                int      `xml:"community_id"`
-	CountryId                  int      `xml:"country_id"`
-	CreatedAt                  string   `xml:"created_at"` // Replace with some sort of marshalled date?
-	FeelingId                  Feeling  `xml:"feeling_id"`
-	Id                         string   `xml:"id"`                          // Empty?                          // Blank
-	IsAutopost                 int      `xml:"is_autopost"`                 // Bool?
-	IsCommunityPrivateAutopost int      `xml:"is_communityPrivateAutopost"` // Bool?
-	IsSpoiler                  int      `xml:"is_spoiler"`                  // Bool?
-	IsAppJumpable              int      `xml:"is_app_jumpable"`             // Bool?
-	EmpathyCount               string   `xml:"empathy_count"`               // Blank?
-	LanguageId                 int      `xml:"language_id"`
-	MiiData                    string   `xml:"mii"`
-	MiiFaceUrl                 string   `xml:"mii_face_url"` // Blank
-	Number                     int      `xml:"number"`
-	PaintingFormat             string   `xml:"painting>format"`
-	PaintingContent            string   `xml:"painting>content"` // Blank
-	PaintingSize               int      `xml:"painting>size"`
-	PaintingUrl                string   `xml:"painting>url"` // Always "http://botu"?
-	PID                        string   `xml:"pid"`          // Blank
-	PlatformId                 int      `xml:"platform_id"`
-	RegionId                   int      `xml:"region_id"`
-	ReplyCount                 int      `xml:"reply_count"`
-	ScreenName                 string   `xml:"screen_name"`
-	TitleId                    string   `xml:"title_id"` // Blank
+	XMLName                    xml.Name   `xml:"post"`
+	Body                       string     `xml:"body"`
+	CommunityId                int        `xml:"community_id"`
+	CountryId                  CountryId  `xml:"country_id"`
+	CreatedAt                  string     `xml:"created_at"` // Replace with some sort of marshalled date?
+	FeelingId                  Feeling    `xml:"feeling_id"`
+	Id                         string     `xml:"id"`                          // Empty?                          // Blank
+	IsAutopost                 int        `xml:"is_autopost"`                 // Bool?
+	IsCommunityPrivateAutopost int        `xml:"is_communityPrivateAutopost"` // Bool?
+	IsSpoiler                  int        `xml:"is_spoiler"`                  // Bool?
+	IsAppJumpable              int        `xml:"is_app_jumpable"`             // Bool?
+	EmpathyCount               string     `xml:"empathy_count"`               // Blank?
+	LanguageId                 LanguageId `xml:"language_id"`
+	MiiData                    string     `xml:"mii"`
+	MiiFaceUrl                 string     `xml:"mii_face_url"` // Blank
+	Number                     int        `xml:"number"`
+	PaintingFormat             string     `xml:"painting>format"`
+	PaintingContent            string     `xml:"painting>content"` // Blank
+	PaintingSize               int        `xml:"painting>size"`
+	PaintingUrl                string     `xml:"painting>url"` // Always "http://botu"?
+	PID                        string     `xml:"pid"`          // Blank
+	PlatformId                 PlatformId `xml:"platform_id"`
+	RegionId                   RegionId   `xml:"region_id"`
+	ReplyCount                 int        `xml:"reply_count"`
+	ScreenName                 string     `xml:"screen_name"`
+	TitleId                    string     `xml:"title_id"` // Blank
+
+	handle PostHandle // identifies the post while the Nup is edited
 }
 
-// Structure for a person
+// Structure for a person, holding every Post by one author
 type Person struct {
 	XMLName xml.Name `xml:"person"`
 	Posts   []Post   `xml:"posts>post"`
+
+	Author string `xml:"-"` // Name the Posts are grouped by, usually the Reddit username
 }
 
 // Structure for a topic
@@ -62,11 +67,13 @@ type Topic struct {
 	IsRecommended    int      `xml:"is_recommended"` // Bool
 	Name             string   `xml:"name"`
 	ParticipantCount uint     `xml:"participant_count"`
-	Posts            []Post   `xml:"people>person>posts>post"`
+	People           []Person `xml:"people>person"`
 	EmpathyCount     int      `xml:"empathy_count"`
 	HasShopPage      int      `xml:"has_shop_page"` // Bool?
 	ModifiedAt       string   `xml:"modified_at"`
 	Position         int      `xml:"position"` // Always 2?
+
+	handle TopicHandle // identifies the topic while the Nup is edited
 }
 
 // Structure for the 1stNUP
@@ -78,7 +85,10 @@ type Nup struct {
 	Expire      string   `xml:"expire"`
 	Topics      []Topic  `xml:"topics>topic"`
 
-	totalPosts uint // keep track of the total number of posts in the structure
+	mu         sync.Mutex // guards the structure while it is edited
+	totalPosts uint       // keep track of the total number of posts in the structure
+	limits     Limits     // caps enforced when editing the structure
+	lastHandle uint64     // last handle given to a topic or post
 }
 
 // List of "characters" that need to be unreplaced
@@ -93,6 +103,7 @@ var replaceTable = []badChar{
 	{"&#39;", "'"},
 }
 
+// Title IDs given to Topics that are not for a game, marked as placeholders in the title catalog
 var defaultTitleIds = []uint{
 	1407581310509322,
 	1407443871760640,
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/xml"
 	"errors"
+	"fmt"
 	"io"
 	"log"
 	"os"
@@ -22,6 +23,7 @@ func InitNup() *Nup {
 		Topics:      []Topic{},
 
 		totalPosts: 0,
+		limits:     DefaultLimits,
 	}
 
 	return &ret
@@ -29,44 +31,83 @@ func InitNup() *Nup {
 
 // Topic Methods
 // Adds an empty Topic with a specified name to the Nup
+// The Topic is given the first default title ID not already used by another Topic
 func (n *Nup) AddTopic(name string) error {
-	if n == nil {
-		return errors.New("no Nup provided")
+	_, err := n.NewTopic(name)
+	return err
+}
+
+// Adds an empty Topic for a game in the title catalog. The Topic is named after the game
+// If a region is provided, the release from that region is used
+func (n *Nup) AddTopicForTitle(gameName string, region ...Region) error {
+	_, err := n.NewTopicForTitle(gameName, region...)
+	return err
+}
+
+// Checks if any Topic in the Nup already uses a title ID
+func (n *Nup) hasTitleId(titleId uint) bool {
+	for i := range n.Topics {
+		if n.Topics[i].TitleId == titleId {
+			return true
+		}
 	}
-	if len(n.Topics) > 10 {
-		return errors.New("too many Topics")
+	return false
+}
+
+// Adds an empty Topic with the given name, title ID and icon. The Nup must be locked
+func (n *Nup) addTopic(name string, titleId uint, icon string) (TopicHandle, error) {
+	if err := n.currentLimits().checkAddTopic(n); err != nil {
+		return 0, err
 	}
 
 	for i := 0; i < len(n.Topics); i++ {
 		if n.Topics[i].Name == name {
-			return errors.New("Topic with this name already exists")
+			return 0, fmt.Errorf("%w: name %s", ErrTopicExists, name)
 		}
 	}
+	if n.hasTitleId(titleId) {
+		return 0, fmt.Errorf("%w: title ID %s", ErrTopicExists, strconv.FormatUint(uint64(titleId), 10))
+	}
 
 	t := Topic{
-		Icon:             defaultIcon,
-		TitleId:          defaultTitleIds[len(n.Topics)],
+		Icon:             icon,
+		TitleId:          titleId,
 		CommunityId:      defaultCommunityId,
 		IsRecommended:    0,
 		Name:             name,
 		ParticipantCount: 0,
-		Posts:            []Post{},
+		People:           []Person{},
 		EmpathyCount:     0,
 		HasShopPage:      0,
 		ModifiedAt:       time.Now().Format("2006-01-02 15:04:05"),
 		Position:         2,
+
+		handle: TopicHandle(n.newHandle()),
 	}
 
 	n.Topics = append(n.Topics, t)
 
-	return nil
+	return t.handle, nil
 }
 
-// Returns a Topic with a given name from the Nup
-func (n *Nup) GetTopic(name string) (*Topic, error) {
+// Returns a copy of the Topic with a given name, including its Posts
+// Changing the copy does not change the Nup, see TopicHandle and EditTopic
+func (n *Nup) GetTopic(name string) (Topic, error) {
 	if n == nil {
-		return nil, errors.New("no Nup provided")
+		return Topic{}, errors.New("no Nup provided")
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	t, err := n.getTopic(name)
+	if err != nil {
+		return Topic{}, err
 	}
+	return t.clone(), nil
+}
+
+// Returns a Topic with a given name from the Nup. The Nup must be locked
+func (n *Nup) getTopic(name string) (*Topic, error) {
 	for i, t := range n.Topics {
 		if t.Name == name {
 			return &n.Topics[i], nil
@@ -81,9 +122,12 @@ func (n *Nup) RemoveTopic(name string) error {
 	if n == nil {
 		return errors.New("no Nup provided")
 	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
 
 	for i, t := range n.Topics {
 		if t.Name == name {
+			n.totalPosts -= t.PostCount()
 			n.Topics = append(n.Topics[:i], n.Topics[i+1:]...)
 			return nil
 		}
@@ -92,52 +136,198 @@ func (n *Nup) RemoveTopic(name string) error {
 	return errors.New("could not find Topic with name " + name)
 }
 
-// Adds an empty post to a topic
-func (n *Nup) AddPost(topicName string) (*Post, error) {
-	t, err := n.GetTopic(topicName)
+// Adds a post to a topic and returns its handle
+// Without options the post is a blank post by "Blank". The options are applied before the topic is touched,
+// so if any of them fails nothing is added
+func (n *Nup) AddPost(topicName string, opts ...PostOption) (PostHandle, error) {
+	if n == nil {
+		return 0, errors.New("no Nup provided")
+	}
+	post, err := buildPost(opts)
 	if err != nil {
+		return 0, err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	t, err := n.getTopic(topicName)
+	if err != nil {
+		return 0, err
+	}
+	added, err := n.addPost(t, post)
+	if err != nil {
+		return 0, err
+	}
+	return added.handle, nil
+}
+
+// Adds an empty post by an author to a topic and returns its handle. Posts by the same author are grouped under one Person
+// Same as AddPost with WithAuthor
+func (n *Nup) AddPostBy(topicName string, author string) (PostHandle, error) {
+	return n.AddPost(topicName, WithAuthor(author))
+}
+
+// Adds a post to a topic, grouped under the Person of its screen name. The Nup must be locked
+func (n *Nup) addPost(t *Topic, post Post) (*Post, error) {
+	author := post.ScreenName
+	if err := n.currentLimits().checkAddPost(n, t, author); err != nil {
 		return nil, err
 	}
-	if n.totalPosts >= 260 {
-		return nil, errors.New("too many Posts")
-	}
-	t.Posts = append(t.Posts, Post{
-		Body:        "Blank post",
-		CommunityId: int(t.CommunityId),
-		CountryId:   1,
-		CreatedAt:   time.Now().Format("2006-01-02 15:04:05"),
-		FeelingId:   FEELING_DEFAULT,
-		LanguageId:  1,
-		MiiData:     defaultMii,
-		PlatformId:  1,
-		ScreenName:  "Blank",
-		PaintingUrl: "http://botu",
-	})
+
+	p := t.GetPerson(author)
+	if p == nil {
+		t.People = append(t.People, Person{Author: author, Posts: []Post{}})
+		p = &t.People[len(t.People)-1]
+	}
+	post.CommunityId = int(t.CommunityId)
+	post.handle = PostHandle(n.newHandle())
+	p.Posts = append(p.Posts, post)
+	t.ParticipantCount = uint(len(t.People))
 	n.totalPosts++
-	return &t.Posts[len(t.Posts)-1], nil
+	return &p.Posts[len(p.Posts)-1], nil
 }
 
 // Removes a post from a topic
+// Posts are indexed in the order they appear in the topic, person by person
 func (n *Nup) RemovePost(topicName string, index uint) error {
-	t, err := n.GetTopic(topicName)
+	if n == nil {
+		return errors.New("no Nup provided")
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	t, err := n.getTopic(topicName)
 	if err != nil {
 		return err
 	}
 
-	if index >= MAX_POSTS {
+	if index >= t.PostCount() {
 		var msg strings.Builder
 		msg.WriteString("index ")
 		msg.WriteString(strconv.FormatUint(uint64(index), 10))
 		msg.WriteString(" out of range for topic ")
 		msg.WriteString(topicName)
-		return errors.New(msg.String())
+		return fmt.Errorf("%w: %s", ErrOutOfRange, msg.String())
+	}
+
+	for i := range t.People {
+		if index >= uint(len(t.People[i].Posts)) {
+			index -= uint(len(t.People[i].Posts))
+			continue
+		}
+		n.removePost(t, i, int(index))
+		break
+	}
+
+	return nil
+}
+
+// Removes the post at an index of a person in a topic. The Nup must be locked
+func (n *Nup) removePost(t *Topic, person int, index int) {
+	p := &t.People[person]
+	p.Posts = append(p.Posts[:index], p.Posts[index+1:]...)
+	if len(p.Posts) == 0 {
+		t.People = append(t.People[:person], t.People[person+1:]...)
+	}
+	t.ParticipantCount = uint(len(t.People))
+	n.totalPosts--
+}
+
+// Returns the number of posts in a topic
+func (t *Topic) PostCount() uint {
+	count := uint(0)
+	for i := range t.People {
+		count += uint(len(t.People[i].Posts))
 	}
+	return count
+}
 
-	t.Posts = append(t.Posts[:index], t.Posts[index+1:]...)
+// Returns pointers to every post in a topic, person by person
+func (t *Topic) Posts() []*Post {
+	ret := make([]*Post, 0, t.PostCount())
+	for i := range t.People {
+		for j := range t.People[i].Posts {
+			ret = append(ret, &t.People[i].Posts[j])
+		}
+	}
+	return ret
+}
 
+// Returns the Person for an author, or nil if the author has no posts in the topic
+func (t *Topic) GetPerson(author string) *Person {
+	for i := range t.People {
+		if t.People[i].Author == author {
+			return &t.People[i]
+		}
+	}
 	return nil
 }
 
+// Parses a Nup from XML
+// The Nup enforces DefaultLimits, raised where it already holds more, so a Nup built with raised limits can be
+// read back and edited. See ParseNupWithLimits to enforce other limits
+func ParseNup(data []byte) (*Nup, error) {
+	n, err := parseNup(data)
+	if err != nil {
+		return nil, err
+	}
+	n.limits = DefaultLimits.raisedTo(n)
+	return n, nil
+}
+
+// Parses a Nup from XML that enforces the given limits, see SetLimits. Fails if the Nup breaks them
+func ParseNupWithLimits(data []byte, l Limits) (*Nup, error) {
+	n, err := parseNup(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := n.SetLimits(l); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// Parses a Nup from XML without setting its limits
+func parseNup(data []byte) (*Nup, error) {
+	n := &Nup{}
+	if err := xml.Unmarshal(data, n); err != nil {
+		return nil, err
+	}
+
+	for i := range n.Topics {
+		t := &n.Topics[i]
+		t.handle = TopicHandle(n.newHandle())
+		// People without Posts are dropped, so ParticipantCount counts only authors who posted
+		people := t.People[:0]
+		for j := range t.People {
+			p := t.People[j]
+			if len(p.Posts) == 0 {
+				continue
+			}
+			p.Author = p.Posts[0].ScreenName
+			for k := range p.Posts {
+				p.Posts[k].handle = PostHandle(n.newHandle())
+			}
+			people = append(people, p)
+		}
+		t.People = people
+		t.ParticipantCount = uint(len(t.People))
+		n.totalPosts += t.PostCount()
+	}
+
+	return n, nil
+}
+
+// Reads and parses a Nup from an XML file
+func ReadNup(path string) (*Nup, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseNup(data)
+}
+
 // Temporary, for testing only
 func SetAllMiis(xmlPath string, miiString string) {
 	xmlInBytes, err := os.ReadFile(xmlPath)
@@ -152,8 +342,8 @@ func SetAllMiis(xmlPath string, miiString string) {
 	}
 
 	for t := range x.Topics {
-		for q := range x.Topics[t].Posts {
-			x.Topics[t].Posts[q].MiiData = miiString
+		for _, p := range x.Topics[t].Posts() {
+			p.MiiData = miiString
 		}
 	}
 
@@ -195,7 +385,9 @@ func (n *Nup) Render(outputName ...string) (string, error) {
 		return nil
 	}
 
+	n.mu.Lock()
 	xmlBytes, err := xml.MarshalIndent(n, "", "  ")
+	n.mu.Unlock()
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,246 @@
+package libwara
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func TestGetTopicCopy(t *testing.T) {
+	n, _, _ := testNup(t, "a")
+
+	topic, err := n.GetTopic("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	topic.Name = "changed"
+	topic.People[0].Posts[0].Body = "changed"
+
+	again, err := n.GetTopic("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.People[0].Posts[0].Body != "by a" {
+		t.Error("changing the returned Topic changed the Nup")
+	}
+	if _, err := n.GetTopic("changed"); err == nil {
+		t.Error("renaming the returned Topic renamed the Topic in the Nup")
+	}
+}
+
+func TestAddPostBy(t *testing.T) {
+	n, topic, _ := testNup(t, "a")
+
+	h, err := n.AddPostBy("test", "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The handle stays valid while more Posts are added
+	for _, author := range []string{"b", "c", "a"} {
+		if _, err := n.AddPostBy("test", author); err != nil {
+			t.Fatal(err)
+		}
+	}
+	post, err := n.Post(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if post.ScreenName != "b" {
+		t.Errorf("got a Post by %q", post.ScreenName)
+	}
+	if authors := authorsOf(t, n, topic); len(authors) != 5 || authors[1] != "a" || authors[2] != "b" || authors[3] != "b" {
+		t.Errorf("Posts were not grouped by author: %v", authors)
+	}
+
+	if _, err := n.AddPostBy("test", ""); !errors.Is(err, ErrInvalidPost) {
+		t.Errorf("got %v for an empty author", err)
+	}
+	if _, err := n.AddPostBy("missing", "a"); err == nil {
+		t.Error("added a Post to a missing Topic")
+	}
+}
+
+// Returns a rendered Nup built from the default Topic, Post, Mii and icon
+func defaultNupXML(t testing.TB) []byte {
+	t.Helper()
+	n := InitNup()
+	topic, err := n.NewTopic("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, author := range []string{"a", "b", "a"} {
+		if _, err := n.NewPost(topic, WithAuthor(author), WithBody("line \"one\"\nline 'two' & <three>")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := n.NewPost(topic); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := n.NewTopic("empty"); err != nil {
+		t.Fatal(err)
+	}
+	out, err := n.Render()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []byte(out)
+}
+
+func FuzzParseNup(f *testing.F) {
+	rendered := defaultNupXML(f)
+	f.Add(rendered)
+	f.Add(rendered[:len(rendered)/2])
+	f.Add([]byte(`<result><topics><topic><people><person><posts></posts></person></people></topic></topics></result>`))
+	f.Add([]byte(`<result><topics><topic><name>&#34;&#xA;&#39;</name></topic></topics></result>`))
+	f.Add([]byte{})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		n, err := ParseNup(data)
+		if err != nil {
+			return
+		}
+		first, err := n.Render()
+		if err != nil {
+			t.Fatal(err)
+		}
+		again, err := ParseNup([]byte(first))
+		if err != nil {
+			t.Fatalf("could not parse a rendered Nup: %v\n%s", err, first)
+		}
+		second, err := again.Render()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal([]byte(first), []byte(second)) {
+			t.Fatalf("rendering is not stable:\n%s\n%s", first, second)
+		}
+	})
+}
+
+// Returns a Nup that renders the same every time, with Posts by several authors in a Topic with its own community ID
+func goldenNup(t *testing.T) *Nup {
+	t.Helper()
+	n := InitNup()
+	created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	topic, err := n.NewTopic("golden")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = n.EditTopic(topic, func(t *Topic) error {
+		t.CommunityId = 12345
+		t.EmpathyCount = 7
+		t.ModifiedAt = "2020-01-02 03:04:05"
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	posts := [][]PostOption{
+		{WithAuthor("alice"), WithBody("line \"one\"\nline 'two' & <three>")},
+		{WithAuthor("bob"), WithBody("by bob"), WithFeeling(FEELING_EXCITED)},
+		{WithAuthor("alice"), WithBody("again"), WithSpoiler()},
+	}
+	for i, opts := range posts {
+		opts = append(opts, WithCreatedAt(created.Add(time.Duration(i)*time.Minute)))
+		if _, err := n.NewPost(topic, opts...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	empty, err := n.NewTopic("empty")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = n.EditTopic(empty, func(t *Topic) error {
+		t.ModifiedAt = "2020-01-02 03:04:05"
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+// Returns a copy of the Topics of a Nup without the fields that parsing does not keep, handles and XML names
+func comparableTopics(n *Nup) []Topic {
+	ret := make([]Topic, len(n.Topics))
+	for i := range n.Topics {
+		ret[i] = n.Topics[i].clone()
+		ret[i].XMLName = xml.Name{}
+		ret[i].handle = 0
+		for j := range ret[i].People {
+			p := &ret[i].People[j]
+			p.XMLName = xml.Name{}
+			for k := range p.Posts {
+				p.Posts[k].XMLName = xml.Name{}
+				p.Posts[k].handle = 0
+			}
+		}
+	}
+	return ret
+}
+
+func TestRenderGolden(t *testing.T) {
+	n := goldenNup(t)
+	out, err := n.Render()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join("testdata", "nup.xml")
+	if *update {
+		if err := os.WriteFile(path, []byte(out), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	golden, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal([]byte(out), golden) {
+		t.Errorf("rendered Nup does not match %s, run the tests with -update to rewrite it:\n%s", path, out)
+	}
+}
+
+func TestParseNupRoundTrip(t *testing.T) {
+	n := goldenNup(t)
+	out, err := n.Render()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseNup([]byte(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if parsed.Version != n.Version || parsed.RequestName != n.RequestName || parsed.Expire != n.Expire {
+		t.Errorf("got header %d %q %q", parsed.Version, parsed.RequestName, parsed.Expire)
+	}
+	if got, want := comparableTopics(parsed), comparableTopics(n); !reflect.DeepEqual(got, want) {
+		t.Errorf("parsed Topics differ from the rendered ones:\n%+v\n%+v", got, want)
+	}
+
+	// Spot check the grouping, as DeepEqual failures are hard to read
+	topic, err := parsed.GetTopic("golden")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(topic.People) != 2 || topic.People[0].Author != "alice" || len(topic.People[0].Posts) != 2 || topic.People[1].Author != "bob" {
+		t.Errorf("Posts were not grouped by author: %+v", topic.People)
+	}
+	if topic.CommunityId != 12345 || topic.People[1].Posts[0].CommunityId != 12345 {
+		t.Errorf("community ID was not kept: %d, %d", topic.CommunityId, topic.People[1].Posts[0].CommunityId)
+	}
+	if parsed.totalPosts != 3 {
+		t.Errorf("counted %d Posts", parsed.totalPosts)
+	}
+}
@@ -1,9 +1,17 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"image/png"
 	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"cornchip.com/libwara/v2"
+	"cornchip.com/reddittowara/v2/ingest"
 )
 
 func checkError(err error) {
@@ -12,13 +20,289 @@ func checkError(err error) {
 	}
 }
 
-func main() {
+// Lists the title catalog, or the titles matching a search query
+func listTitles(args []string) {
+	titles := libwara.Titles()
+	if len(args) > 0 {
+		titles = libwara.SearchTitles(strings.Join(args, " "))
+	}
+
+	for _, t := range titles {
+		fmt.Printf("%016X  %-3s  %s\n", t.TitleId, t.Region, t.Name)
+	}
+}
+
+// Builds a 1stNUP from a config file, or the test 1stNUP if no config file is given
+// The 1stNUP is written to 1stNUP.xml unless another output file is given after the config file
+func build(args []string) {
+	if len(args) > 2 {
+		log.Fatal("usage: build [config.json] [out.xml]")
+	}
+	if len(args) == 0 {
+		nup := libwara.InitNup()
+		err := nup.AddTopic("test")
+		checkError(err)
+
+		_, err = nup.AddPost("test")
+		checkError(err)
+
+		nup.Render("1stNUP.xml")
+		return
+	}
+
+	config, err := ingest.LoadConfig(args[0])
+	checkError(err)
+	out := "1stNUP.xml"
+	if len(args) > 1 {
+		out = args[1]
+	}
+
+	ctx := context.Background()
+	builder := ingest.NewBuilder()
+	builder.CommentsPerItem = config.Comments
+	builder.Workers = config.Workers
+	if config.Dither != "" {
+		builder.Dither, err = libwara.DitherByName(config.Dither)
+		checkError(err)
+	}
+
+	client := ingest.NewClient()
+	if config.OAuth != nil {
+		client = ingest.NewOAuthClient(*config.OAuth)
+	}
+	client.CacheDir = ".cache"
+	// Builds from a dump stay offline, the client is only used for feeds and outboxes
+	if config.Dump == nil {
+		builder.Client = client
+	}
+
+	if config.History != "" {
+		builder.History, err = ingest.OpenHistory(config.History)
+		checkError(err)
+		defer builder.History.Close()
+		// The absolute path keeps 1stNUPs built into different directories apart
+		builder.NupName, err = filepath.Abs(out)
+		checkError(err)
+	}
+
+	for author, path := range config.Miis {
+		m, err := ingest.ReadMiiQR(path)
+		checkError(err)
+		builder.SetMii(author, m)
+	}
+
+	src, err := config.Source(client)
+	checkError(err)
+
 	nup := libwara.InitNup()
-	err := nup.AddTopic("test")
+	checkError(nup.SetLimits(config.Limits))
+	checkError(builder.Build(ctx, nup, src))
+
+	_, err = nup.Render(out)
+	checkError(err)
+}
+
+// Prints the Topics of a 1stNUP and their Posts, with the indices the editing commands take
+func listPosts(args []string) {
+	if len(args) != 1 {
+		log.Fatal("usage: posts <1stNUP.xml>")
+	}
+	nup, err := libwara.ReadNup(args[0])
+	checkError(err)
+
+	nup.EachTopic(func(h libwara.TopicHandle, t *libwara.Topic) bool {
+		fmt.Printf("%s\n", t.Name)
+		for i, p := range t.Posts() {
+			body, _, _ := strings.Cut(p.Body, "\n")
+			fmt.Printf("  %3d  %s  %-16s  %s\n", i, p.CreatedAt, p.ScreenName, body)
+		}
+		return true
+	})
+}
+
+// Returns the handle of the Post at an index of a Topic, as printed by the posts command
+func postAt(nup *libwara.Nup, topicName string, index string) libwara.PostHandle {
+	topic, err := nup.TopicHandle(topicName)
+	checkError(err)
+	posts, err := nup.PostHandles(topic)
+	checkError(err)
+
+	i, err := strconv.Atoi(index)
+	checkError(err)
+	if i < 0 || i >= len(posts) {
+		log.Fatal("no post " + index + " in topic " + topicName)
+	}
+	return posts[i]
+}
+
+// Rearranges the Topics and Posts of a 1stNUP in place
+func edit(command string, args []string) {
+	usage := map[string]string{
+		"sort":  "sort <1stNUP.xml> <topic> <time|score|author>",
+		"swap":  "swap <1stNUP.xml> <topic> <index> <index>",
+		"move":  "move <1stNUP.xml> <topic> <index> <to topic>",
+		"order": "order <1stNUP.xml> <topic>...",
+	}
+	counts := map[string]int{"sort": 3, "swap": 4, "move": 4}
+	if want, ok := counts[command]; (ok && len(args) != want) || len(args) < 2 {
+		log.Fatal("usage: " + usage[command])
+	}
+
+	nup, err := libwara.ReadNup(args[0])
+	checkError(err)
+
+	switch command {
+	case "sort":
+		topic, err := nup.TopicHandle(args[1])
+		checkError(err)
+		by, err := libwara.SortByName(args[2])
+		checkError(err)
+		checkError(nup.SortPosts(topic, by))
+	case "swap":
+		checkError(nup.SwapPosts(postAt(nup, args[1], args[2]), postAt(nup, args[1], args[3])))
+	case "move":
+		to, err := nup.TopicHandle(args[3])
+		checkError(err)
+		checkError(nup.MovePost(postAt(nup, args[1], args[2]), to))
+	case "order":
+		// Listed Topics go first, in the given order, followed by the rest as they were
+		order := []libwara.TopicHandle{}
+		listed := map[libwara.TopicHandle]bool{}
+		for _, name := range args[1:] {
+			h, err := nup.TopicHandle(name)
+			checkError(err)
+			order = append(order, h)
+			listed[h] = true
+		}
+		for _, h := range nup.TopicHandles() {
+			if !listed[h] {
+				order = append(order, h)
+			}
+		}
+		checkError(nup.ReorderTopics(order))
+	}
+
+	_, err = nup.Render(args[0])
+	checkError(err)
+}
+
+// Combines 1stNUPs into one, see libwara.MergeStrategyByName for the strategies
+func merge(args []string) {
+	if len(args) < 3 {
+		log.Fatal("usage: merge <out.xml> <rename|merge|keep-first> <1stNUP.xml>...")
+	}
+	strategy, err := libwara.MergeStrategyByName(args[1])
+	checkError(err)
+
+	nups := []*libwara.Nup{}
+	for _, path := range args[2:] {
+		nup, err := libwara.ReadNup(path)
+		checkError(err)
+		nups = append(nups, nup)
+	}
+
+	merged, err := libwara.MergeNups(strategy, nups...)
+	checkError(err)
+	_, err = merged.Render(args[0])
 	checkError(err)
+}
 
-	_, err = nup.AddPost("test")
+// Prints what changed between two 1stNUPs. Exits with status 1 if they differ
+func diff(args []string) {
+	if len(args) != 2 {
+		log.Fatal("usage: diff <old.xml> <new.xml>")
+	}
+	a, err := libwara.ReadNup(args[0])
+	checkError(err)
+	b, err := libwara.ReadNup(args[1])
 	checkError(err)
 
-	nup.Render("1stNUP.xml")
+	describe := func(p *libwara.Post) string {
+		body, _, _ := strings.Cut(p.Body, "\n")
+		return p.ScreenName + " at " + p.CreatedAt + ": " + body
+	}
+
+	changes := libwara.DiffNups(a, b)
+	for _, t := range changes {
+		fmt.Printf("%s topic %s", t.Kind, t.Name)
+		if len(t.Fields) > 0 {
+			fmt.Printf(" (%s)", strings.Join(t.Fields, ", "))
+		}
+		fmt.Println()
+
+		for _, p := range t.Posts {
+			switch p.Kind {
+			case libwara.Added:
+				fmt.Printf("  + %s\n", describe(p.New))
+			case libwara.Removed:
+				fmt.Printf("  - %s\n", describe(p.Old))
+			default:
+				fmt.Printf("  ~ %s (%s)\n", describe(p.New), strings.Join(p.Fields, ", "))
+			}
+		}
+	}
+
+	if len(changes) > 0 {
+		os.Exit(1)
+	}
+}
+
+// Writes the QR code of the Mii a Reddit user gets, which a 3DS or Wii U can scan
+func exportMii(args []string) {
+	if len(args) != 2 {
+		log.Fatal("usage: qr <author> <out.png>")
+	}
+	m, err := ingest.NewAuthorMii(args[0])
+	checkError(err)
+	img, err := libwara.ExportMiiQR(m)
+	checkError(err)
+
+	f, err := os.Create(args[1])
+	checkError(err)
+	// log.Fatal skips deferred calls, so the file is closed before any error is checked
+	err = png.Encode(f, img)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	checkError(err)
+}
+
+// Prints the Mii in an image of a Mii QR code, encoded as in a 1stNUP
+func scanMii(args []string) {
+	if len(args) != 1 {
+		log.Fatal("usage: scan <qr.png>")
+	}
+	m, err := ingest.ReadMiiQR(args[0])
+	checkError(err)
+
+	fmt.Printf("%s by %s\n", m.GetMiiName(), m.GetCreatorName())
+	fmt.Println(m.Encode())
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		build(nil)
+		return
+	}
+
+	switch os.Args[1] {
+	case "build":
+		build(os.Args[2:])
+	case "titles":
+		listTitles(os.Args[2:])
+	case "posts":
+		listPosts(os.Args[2:])
+	case "sort", "swap", "move", "order":
+		edit(os.Args[1], os.Args[2:])
+	case "merge":
+		merge(os.Args[2:])
+	case "diff":
+		diff(os.Args[2:])
+	case "qr":
+		exportMii(os.Args[2:])
+	case "scan":
+		scanMii(os.Args[2:])
+	default:
+		log.Fatal("unknown command " + os.Args[1] + ", expected build, titles, posts, sort, swap, move, order, merge, diff, qr or scan")
+	}
 }